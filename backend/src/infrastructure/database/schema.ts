@@ -1,4 +1,4 @@
-import { pgTable, varchar, timestamp, uuid, jsonb, decimal, boolean, text, integer } from 'drizzle-orm/pg-core';
+import { pgTable, varchar, timestamp, uuid, jsonb, decimal, boolean, text, integer, real, smallint } from 'drizzle-orm/pg-core';
 import { relations } from 'drizzle-orm';
 
 // Users table
@@ -27,10 +27,15 @@ export const products = pgTable('products', {
   id: uuid('id').primaryKey().defaultRandom(),
   name: varchar('name', { length: 500 }).notNull(),
   brand: varchar('brand', { length: 255 }),
+  seller: varchar('seller', { length: 255 }), // marketplace seller, null for stores selling their own stock
   category: varchar('category', { length: 100 }).notNull(), // 'shirt', 'pants', 'dress', etc.
   subcategory: varchar('subcategory', { length: 100 }), // 't-shirt', 'jeans', 'blazer', etc.
   price: decimal('price', { precision: 10, scale: 2 }).notNull(),
   originalPrice: decimal('original_price', { precision: 10, scale: 2 }),
+  installmentCount: integer('installment_count'), // e.g. 10 for "10x sem juros"
+  installmentValue: decimal('installment_value', { precision: 10, scale: 2 }),
+  installmentInterestFree: boolean('installment_interest_free'),
+  cashPrice: decimal('cash_price', { precision: 10, scale: 2 }), // Pix/boleto price
   currency: varchar('currency', { length: 3 }).default('BRL').notNull(),
   imageUrl: text('image_url'),
   productUrl: text('product_url').notNull(),
@@ -39,11 +44,25 @@ export const products = pgTable('products', {
   colors: jsonb('colors').$type<string[]>(), // ['black', 'white', 'blue']
   isLuxury: boolean('is_luxury').default(false).notNull(),
   isEconomic: boolean('is_economic').default(false).notNull(),
+  tierRule: varchar('tier_rule', { length: 150 }), // rule that set isLuxury/isEconomic, e.g. 'dress' or 'percentile:dress:p25-p90'
   isAvailable: boolean('is_available').default(true).notNull(),
+  missedScrapes: integer('missed_scrapes').default(0).notNull(), // consecutive complete scrapes without this product
   source: varchar('source', { length: 100 }).notNull(), // website source
   gender: varchar('gender', { length: 20 }).notNull(), // 'male', 'female', 'unisex'
-  season: varchar('season', { length: 20 }), // 'summer', 'winter', 'spring', 'autumn'
-  weather: jsonb('weather').$type<string[]>(), // ['hot', 'cold', 'rain', 'sunny']
+  genderConfidence: real('gender_confidence'), // 0 when gender is unisex for lack of hints, up to 1
+  isKids: boolean('is_kids').default(false).notNull(), // children's clothing
+  season: varchar('season', { length: 20 }), // 'summer', 'winter', 'spring', 'autumn', 'all'
+  weather: jsonb('weather').$type<string[]>(), // ['hot', 'warm', 'mild', 'cold', 'rain', 'wind']
+  minTemperature: smallint('min_temperature'), // °C
+  maxTemperature: smallint('max_temperature'), // °C
+  material: text('material'), // composition from the product page, e.g. '100% algodão'
+  images: jsonb('images').$type<string[]>(), // gallery images from the product page
+  sizeStock: jsonb('size_stock').$type<Array<{
+    size: string;
+    available: boolean;
+    quantity?: number;
+  }>>(),
+  detailedAt: timestamp('detailed_at'), // last product page enrichment
   scrapedAt: timestamp('scraped_at').defaultNow().notNull(),
   createdAt: timestamp('created_at').defaultNow().notNull(),
   updatedAt: timestamp('updated_at').defaultNow().notNull(),
@@ -129,6 +148,26 @@ export const productPriceHistory = pgTable('product_price_history', {
   recordedAt: timestamp('recorded_at').defaultNow().notNull(),
 });
 
+// Scraper run history, one row per site scraped by a run
+export const scrapeRuns = pgTable('scrape_runs', {
+  id: uuid('id').primaryKey().defaultRandom(),
+  runId: uuid('run_id').notNull(), // shared by the sites of one run
+  trigger: varchar('trigger', { length: 20 }).notNull(), // 'initial', 'scheduled', 'manual'
+  source: varchar('source', { length: 100 }).notNull(),
+  categories: jsonb('categories').$type<string[]>(), // set when only some categories were scraped
+  status: varchar('status', { length: 20 }).notNull(), // 'running', 'succeeded', 'partial', 'failed', 'skipped'
+  startedAt: timestamp('started_at').notNull(),
+  finishedAt: timestamp('finished_at'),
+  productsFound: integer('products_found').default(0).notNull(),
+  productsInserted: integer('products_inserted').default(0).notNull(),
+  productsUpdated: integer('products_updated').default(0).notNull(),
+  productsRejected: integer('products_rejected').default(0).notNull(), // found but not saved, e.g. children's clothing
+  productsUnavailable: integer('products_unavailable').default(0).notNull(),
+  error: text('error'),
+  config: jsonb('config').$type<Record<string, unknown>>(), // scraper settings the run used
+  createdAt: timestamp('created_at').defaultNow().notNull(),
+});
+
 // Relations
 export const usersRelations = relations(users, ({ many }) => ({
   recommendations: many(recommendations),
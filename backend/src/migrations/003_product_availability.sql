@@ -0,0 +1,4 @@
+-- Track consecutive complete scrapes in which a product was not found
+ALTER TABLE products ADD COLUMN IF NOT EXISTS missed_scrapes INTEGER DEFAULT 0 NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_source_scraped_at ON products(source, scraped_at);
@@ -0,0 +1,7 @@
+-- Fields filled by the product detail enrichment pass
+ALTER TABLE products ADD COLUMN IF NOT EXISTS material TEXT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS images JSONB;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS size_stock JSONB;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS detailed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_detailed_at ON products(detailed_at);
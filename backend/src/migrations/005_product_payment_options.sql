@@ -0,0 +1,5 @@
+-- Installment plans and cash (Pix/boleto) prices shown by the stores
+ALTER TABLE products ADD COLUMN IF NOT EXISTS installment_count INTEGER;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS installment_value DECIMAL(10,2);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS installment_interest_free BOOLEAN;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cash_price DECIMAL(10,2);
@@ -0,0 +1,2 @@
+-- Tier rule (configured or percentile) that set is_economic/is_luxury
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tier_rule VARCHAR(150);
@@ -0,0 +1,3 @@
+-- How sure the scraper is about the gender, and children's clothing flag
+ALTER TABLE products ADD COLUMN IF NOT EXISTS gender_confidence REAL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_kids BOOLEAN NOT NULL DEFAULT false;
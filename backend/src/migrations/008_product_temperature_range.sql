@@ -0,0 +1,3 @@
+-- Temperatures, in °C, a product is comfortable in
+ALTER TABLE products ADD COLUMN IF NOT EXISTS min_temperature SMALLINT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS max_temperature SMALLINT;
@@ -0,0 +1,2 @@
+-- Marketplace seller, kept apart from the brand
+ALTER TABLE products ADD COLUMN IF NOT EXISTS seller VARCHAR(255);
@@ -0,0 +1,22 @@
+-- One row per site scraped by a run. Sites scraped by the same run share run_id.
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL,
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('initial', 'scheduled', 'manual')),
+    source VARCHAR(100) NOT NULL,
+    categories JSONB,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'partial', 'failed', 'skipped')),
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    products_found INTEGER DEFAULT 0 NOT NULL,
+    products_inserted INTEGER DEFAULT 0 NOT NULL,
+    products_updated INTEGER DEFAULT 0 NOT NULL,
+    products_rejected INTEGER DEFAULT 0 NOT NULL,
+    products_unavailable INTEGER DEFAULT 0 NOT NULL,
+    error TEXT,
+    config JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_run_id ON scrape_runs(run_id);
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_source_started_at ON scrape_runs(source, started_at DESC);
@@ -0,0 +1,87 @@
+// Command fixtures refreshes the HTML fixtures used by the scraper tests by
+// loading every category page of the configured sites in Chrome.
+//
+//	go run ./cmd/fixtures -site zara
+//	go test ./internal/scraper -update
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	"shinewardrobe-scraper/internal/browser"
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/scraper"
+
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/scraper/testdata/fixtures", "directory to write fixtures to")
+	site := flag.String("site", "", "only refresh fixtures for this source")
+	flag.Parse()
+
+	logger := logrus.WithField("service", "shinewardrobe-fixtures")
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load configuration")
+	}
+
+	definitions, err := scraper.LoadDefinitions(cfg.SitesDir)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid site definitions")
+	}
+
+	pool := browser.NewPool(browser.Options{
+		RemoteURL:    cfg.ChromeRemoteURL,
+		Headless:     cfg.ChromeHeadless,
+		UserAgent:    cfg.UserAgent,
+		ProxyServer:  cfg.ChromeProxy,
+		WindowWidth:  cfg.WindowWidth,
+		WindowHeight: cfg.WindowHeight,
+	}, logger)
+	defer pool.Close()
+
+	fetcher := scraper.NewBrowserFetcher(pool)
+	failed := 0
+
+	for _, definition := range definitions {
+		if *site != "" && definition.Source != *site {
+			continue
+		}
+
+		for _, category := range definition.Categories {
+			categoryLogger := logger.WithFields(logrus.Fields{
+				"site":     definition.Source,
+				"category": category.Name,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			html, err := fetcher.FetchPage(ctx, category.URL, definition.Page)
+			cancel()
+			if err != nil {
+				categoryLogger.WithError(err).Error("Failed to fetch page")
+				failed++
+				continue
+			}
+
+			path := scraper.FixturePath(*dir, definition.Source, category.Name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				categoryLogger.WithError(err).Fatal("Failed to create fixture directory")
+			}
+			if err := os.WriteFile(path, []byte(html), 0o644); err != nil {
+				categoryLogger.WithError(err).Fatal("Failed to write fixture")
+			}
+
+			categoryLogger.WithField("path", path).Info("Fixture refreshed")
+		}
+	}
+
+	if failed > 0 {
+		logger.WithField("failed", failed).Fatal("Some fixtures could not be refreshed")
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"os/signal"
 	"syscall"
 
+	"shinewardrobe-scraper/internal/browser"
 	"shinewardrobe-scraper/internal/config"
 	"shinewardrobe-scraper/internal/database"
+	"shinewardrobe-scraper/internal/fx"
+	"shinewardrobe-scraper/internal/runs"
 	"shinewardrobe-scraper/internal/scheduler"
 	"shinewardrobe-scraper/internal/scraper"
 
@@ -26,6 +29,26 @@ func main() {
 		logger.WithError(err).Fatal("Failed to load configuration")
 	}
 
+	definitions, err := scraper.LoadDefinitions(cfg.SitesDir)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid site definitions")
+	}
+
+	definitions, err = scraper.ApplyBaseURLOverrides(definitions, cfg.BaseURLOverrides)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid base URL overrides")
+	}
+	logger.WithField("sites", len(definitions)).Info("Site definitions loaded")
+
+	tiers, err := scraper.LoadTierConfig(cfg.TiersFile)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid price tier rules")
+	}
+	logger.WithFields(logrus.Fields{
+		"mode":  tiers.Mode,
+		"rules": len(tiers.Rules),
+	}).Info("Price tier rules loaded")
+
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to connect to database")
@@ -34,18 +57,40 @@ func main() {
 
 	logger.Info("Database connected successfully")
 
-	scraperService := scraper.NewService(db, logger)
+	browserPool := browser.NewPool(browser.Options{
+		RemoteURL:    cfg.ChromeRemoteURL,
+		Headless:     cfg.ChromeHeadless,
+		UserAgent:    cfg.UserAgent,
+		ProxyServer:  cfg.ChromeProxy,
+		WindowWidth:  cfg.WindowWidth,
+		WindowHeight: cfg.WindowHeight,
+	}, logger)
+	defer browserPool.Close()
+
+	var rates fx.Provider
+	if cfg.FXRatesFile != "" {
+		staticRates, err := fx.LoadStaticFile(cfg.FXRatesFile)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load FX rates")
+		}
+		rates = staticRates
+		logger.WithField("file", cfg.FXRatesFile).Info("FX rates loaded")
+	}
+
+	scraperService := scraper.NewService(db, browserPool, cfg, definitions, rates, tiers, logger)
 
-	schedulerService := scheduler.NewService(scraperService, cfg.Schedule, logger)
+	schedulerService := scheduler.NewService(scraperService, cfg.Schedules, cfg.Timezone, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	schedulerService.Start(ctx)
+	if err := schedulerService.Start(ctx); err != nil {
+		logger.WithError(err).Fatal("Failed to start scheduler")
+	}
 
 	logger.Info("Running initial scrape...")
-	if err := scraperService.ScrapeAll(ctx); err != nil {
-		logger.WithError(err).Error("Initial scrape failed")
+	if report, err := scraperService.ScrapeAll(ctx, runs.TriggerInitial); err != nil {
+		logger.WithError(err).WithField("run_id", report.RunID).Error("Initial scrape failed")
 	}
 
 	logger.Info("Scraper started successfully. Waiting for scheduled runs...")
@@ -59,4 +104,4 @@ func main() {
 	schedulerService.Stop()
 
 	logger.Info("Scraper stopped successfully")
-}
\ No newline at end of file
+}
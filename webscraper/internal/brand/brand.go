@@ -0,0 +1,144 @@
+// Package brand normalizes brand names across stores and finds brands named
+// in product titles, using the alias dictionary in brands.yaml.
+package brand
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"shinewardrobe-scraper/internal/taxonomy"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed brands.yaml
+var embeddedBrands []byte
+
+var defaultDictionary = mustParse("brands.yaml", embeddedBrands)
+
+// Entry is a brand of the dictionary and the other spellings of its name.
+type Entry struct {
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases"`
+}
+
+type Dictionary struct {
+	// names maps the key of every spelling to the brand name.
+	names map[string]string
+	// spellings are the normalized spellings looked up in product names,
+	// longest first.
+	spellings []spelling
+}
+
+type spelling struct {
+	text string
+	name string
+}
+
+// Default returns the dictionary bundled with the scraper.
+func Default() *Dictionary {
+	return defaultDictionary
+}
+
+// Parse reads a YAML list of entries. A spelling shared by two brands is an
+// error, since it could not tell them apart.
+func Parse(name string, data []byte) (*Dictionary, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var entries []Entry
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode: %w", name, err)
+	}
+
+	dictionary := &Dictionary{names: map[string]string{}}
+	var errs []error
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" {
+			errs = append(errs, fmt.Errorf("%s: entry %d: name is required", name, i))
+			continue
+		}
+		for _, text := range append([]string{entry.Name}, entry.Aliases...) {
+			key := Key(text)
+			if key == "" {
+				errs = append(errs, fmt.Errorf("%s: %s: alias %q has no letters or digits", name, entry.Name, text))
+				continue
+			}
+			if other, exists := dictionary.names[key]; exists && other != entry.Name {
+				errs = append(errs, fmt.Errorf("%s: %s: %q is already a spelling of %s", name, entry.Name, text, other))
+				continue
+			}
+			dictionary.names[key] = entry.Name
+			dictionary.spellings = append(dictionary.spellings, spelling{taxonomy.Normalize(text), entry.Name})
+			if key != taxonomy.Normalize(text) {
+				dictionary.spellings = append(dictionary.spellings, spelling{key, entry.Name})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.SliceStable(dictionary.spellings, func(i, j int) bool {
+		return len(dictionary.spellings[i].text) > len(dictionary.spellings[j].text)
+	})
+	return dictionary, nil
+}
+
+func mustParse(name string, data []byte) *Dictionary {
+	dictionary, err := Parse(name, data)
+	if err != nil {
+		panic(err)
+	}
+	return dictionary
+}
+
+// Key identifies a brand regardless of case, accents, spacing and
+// punctuation, so "Levi's" and "LEVIS" share a key.
+func Key(text string) string {
+	return strings.ReplaceAll(taxonomy.Normalize(text), " ", "")
+}
+
+// Canonical returns the dictionary name of raw, or raw with its whitespace
+// collapsed when the brand is not in the dictionary.
+func (d *Dictionary) Canonical(raw string) string {
+	if name, ok := d.names[Key(raw)]; ok {
+		return name
+	}
+	return strings.Join(strings.Fields(raw), " ")
+}
+
+// Find returns the brand named earliest in text, such as a product name. At
+// the same position the longest spelling wins.
+func (d *Dictionary) Find(text string) (string, bool) {
+	padded := " " + taxonomy.Normalize(text) + " "
+
+	best, bestIndex := "", -1
+	for _, spelling := range d.spellings {
+		index := strings.Index(padded, " "+spelling.text+" ")
+		if index >= 0 && (bestIndex < 0 || index < bestIndex) {
+			best, bestIndex = spelling.name, index
+		}
+	}
+	return best, bestIndex >= 0
+}
+
+var sellerPrefixes = []string{"vendido e entregue por", "vendido por", "entregue por"}
+
+// Seller cleans a seller label such as "Vendido e entregue por Loja X" down
+// to the seller name.
+func Seller(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	for _, prefix := range sellerPrefixes {
+		if strings.HasPrefix(lower, prefix+" ") {
+			text = strings.TrimSpace(text[len(prefix):])
+			break
+		}
+	}
+	return strings.TrimSuffix(text, ".")
+}
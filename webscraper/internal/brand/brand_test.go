@@ -0,0 +1,72 @@
+package brand
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"Levis", "Levi's"},
+		{"LEVI'S", "Levi's"},
+		{"Tommy", "Tommy Hilfiger"},
+		{"tommy  hilfiger", "Tommy Hilfiger"},
+		{"Cia Maritima", "Cia. Marítima"},
+		{"  Blue   Steel ", "Blue Steel"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Default().Canonical(tt.raw); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		found bool
+	}{
+		{"Calça Jeans Levis 501 Original", "Levi's", true},
+		{"Camiseta Tommy Jeans Logo", "Tommy Hilfiger", true},
+		{"Camisa Polo Ralph Lauren Slim", "Polo Ralph Lauren", true},
+		{"Tênis Nike Air Max x Puma", "Nike", true},
+		{"Tênis All Star Chuck Taylor", "Converse", true},
+		{"Camisa Polo Piquet Masculina", "", false},
+		{"Vestido Midi Fila Floral", "Fila", true},
+		{"Camiseta Básica Algodão", "", false},
+	}
+
+	for _, tt := range tests {
+		got, found := Default().Find(tt.name)
+		if got != tt.want || found != tt.found {
+			t.Errorf("Find(%q) = %q, %v, want %q, %v", tt.name, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestParseRejectsSharedSpellings(t *testing.T) {
+	_, err := Parse("brands.yaml", []byte("- name: Tommy Hilfiger\n  aliases: [Tommy]\n- name: Tommy\n"))
+	if err == nil {
+		t.Fatal("expected an error for a spelling shared by two brands")
+	}
+}
+
+func TestSeller(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Vendido e entregue por Loja Oficial Nike.", "Loja Oficial Nike"},
+		{"vendido por  Moda Center", "Moda Center"},
+		{"Americanas", "Americanas"},
+	}
+
+	for _, tt := range tests {
+		if got := Seller(tt.text); got != tt.want {
+			t.Errorf("Seller(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
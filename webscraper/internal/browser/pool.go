@@ -0,0 +1,150 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"github.com/sirupsen/logrus"
+)
+
+type Options struct {
+	// RemoteURL points to an already running Chrome DevTools endpoint
+	// (e.g. ws://chrome:9222). When empty a local Chrome is launched.
+	RemoteURL string
+
+	Headless     bool
+	UserAgent    string
+	ProxyServer  string
+	WindowWidth  int
+	WindowHeight int
+}
+
+// Pool owns a single Chrome instance and hands out tabs on it. The browser is
+// started lazily and relaunched when it crashes or the connection is lost.
+type Pool struct {
+	opts   Options
+	logger *logrus.Entry
+
+	mu            sync.Mutex
+	browserCtx    context.Context
+	browserCancel context.CancelFunc
+	allocCancel   context.CancelFunc
+	starts        int
+}
+
+func NewPool(opts Options, logger *logrus.Entry) *Pool {
+	return &Pool{
+		opts:   opts,
+		logger: logger.WithField("component", "browser"),
+	}
+}
+
+// NewTab opens a new tab that is closed when the returned cancel function is
+// called, when ctx is done or when the timeout expires.
+func (p *Pool) NewTab(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	browserCtx, err := p.browser()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tabCtx, tabCancel := chromedp.NewContext(browserCtx)
+	stop := context.AfterFunc(ctx, tabCancel)
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(tabCtx, timeout)
+	cancel := func() {
+		stop()
+		timeoutCancel()
+		tabCancel()
+	}
+
+	var actions []chromedp.Action
+	if p.opts.RemoteURL != "" && p.opts.UserAgent != "" {
+		// Launch flags do not apply to a remote browser, so override per tab.
+		actions = append(actions, emulation.SetUserAgentOverride(p.opts.UserAgent))
+	}
+
+	if err := chromedp.Run(timeoutCtx, actions...); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to open tab: %w", err)
+	}
+
+	return timeoutCtx, cancel, nil
+}
+
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.shutdown()
+	p.logger.Info("Browser closed")
+}
+
+func (p *Pool) browser() (context.Context, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.browserCtx != nil && p.browserCtx.Err() == nil {
+		return p.browserCtx, nil
+	}
+
+	if p.browserCtx != nil {
+		p.logger.Warn("Browser is no longer running, restarting...")
+		p.shutdown()
+	}
+
+	var allocCtx context.Context
+	if p.opts.RemoteURL != "" {
+		allocCtx, p.allocCancel = chromedp.NewRemoteAllocator(context.Background(), p.opts.RemoteURL)
+	} else {
+		allocCtx, p.allocCancel = chromedp.NewExecAllocator(context.Background(), p.allocatorOptions()...)
+	}
+
+	p.browserCtx, p.browserCancel = chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(p.browserCtx); err != nil {
+		p.shutdown()
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	p.starts++
+	p.logger.WithFields(logrus.Fields{
+		"remote": p.opts.RemoteURL != "",
+		"starts": p.starts,
+	}).Info("Browser started")
+
+	return p.browserCtx, nil
+}
+
+func (p *Pool) shutdown() {
+	if p.browserCancel != nil {
+		p.browserCancel()
+	}
+	if p.allocCancel != nil {
+		p.allocCancel()
+	}
+	p.browserCtx, p.browserCancel, p.allocCancel = nil, nil, nil
+}
+
+func (p *Pool) allocatorOptions() []chromedp.ExecAllocatorOption {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", p.opts.Headless),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+	)
+
+	if p.opts.UserAgent != "" {
+		opts = append(opts, chromedp.UserAgent(p.opts.UserAgent))
+	}
+	if p.opts.ProxyServer != "" {
+		opts = append(opts, chromedp.ProxyServer(p.opts.ProxyServer))
+	}
+	if p.opts.WindowWidth > 0 && p.opts.WindowHeight > 0 {
+		opts = append(opts, chromedp.WindowSize(p.opts.WindowWidth, p.opts.WindowHeight))
+	}
+
+	return opts
+}
@@ -0,0 +1,381 @@
+// Package climate estimates the weather and season a garment suits, using the
+// temperature bands of the backend's weather service: hot above 30°C, warm
+// above 20°C, mild above 10°C and cold below.
+package climate
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"shinewardrobe-scraper/internal/taxonomy"
+)
+
+// Weather tags.
+const (
+	Hot  = "hot"
+	Warm = "warm"
+	Mild = "mild"
+	Cold = "cold"
+	Rain = "rain"
+	Wind = "wind"
+)
+
+// Seasons. AllSeasons is used for garments that span warm and cold weather.
+const (
+	Summer     = "summer"
+	Autumn     = "autumn"
+	Winter     = "winter"
+	Spring     = "spring"
+	AllSeasons = "all"
+)
+
+// Garment holds what the model can look at.
+type Garment struct {
+	Category    string
+	Subcategory string
+	Name        string
+	Description string
+	// Material is the composition, such as "55% linho, 45% viscose".
+	Material string
+}
+
+type Suitability struct {
+	Weather []string
+	// MinTemp and MaxTemp bound the comfortable temperatures in °C.
+	MinTemp int
+	MaxTemp int
+	Season  string
+}
+
+// Model assesses garments. ScoringModel is the default implementation.
+type Model interface {
+	Assess(garment Garment) Suitability
+}
+
+const (
+	lowestTemp  = -5
+	highestTemp = 40
+	// comfortSpread is how far from its ideal temperature a garment stays
+	// comfortable.
+	comfortSpread = 7
+	// minOverlap is how many degrees of a band a range must cover to get its
+	// tag, so ranges touching a band edge do not count.
+	minOverlap = 2
+)
+
+var bands = []struct {
+	tag      string
+	min, max int
+}{
+	{Hot, 30, highestTemp},
+	{Warm, 20, 30},
+	{Mild, 10, 20},
+	{Cold, lowestTemp, 10},
+}
+
+// ScoringModel scores how warm a garment is, from 0 for swimwear to 10 for a
+// padded coat, and maps the score to a temperature range. The score starts
+// from the category and is adjusted for the composition, sleeve length and
+// fabric weight.
+type ScoringModel struct{}
+
+var (
+	compositionPattern  = regexp.MustCompile(`(\d{1,3})\s*%\s*(?:de\s+)?([\p{L}]+(?:\s+[\p{L}]+)?)`)
+	fabricWeightPattern = regexp.MustCompile(`(\d{2,3})\s*(?:g|gr|gramas)\s*/\s*m`)
+)
+
+func (ScoringModel) Assess(garment Garment) Suitability {
+	text := taxonomy.Normalize(strings.Join([]string{garment.Name, garment.Description, garment.Material}, " "))
+
+	if neutral(garment) {
+		return Suitability{
+			Weather: []string{Hot, Warm, Mild, Cold},
+			MinTemp: lowestTemp,
+			MaxTemp: highestTemp,
+			Season:  AllSeasons,
+		}
+	}
+
+	score := baseWarmth(garment) +
+		materialWarmth(garment, text) +
+		adjustment(text, sleeveWarmth) +
+		fabricWeightWarmth(garment, text) +
+		adjustment(text, seasonWarmth)
+	score = math.Max(0, math.Min(10, score))
+
+	// A score of 0 is most comfortable at 32°C and every point lowers that
+	// by 3°C.
+	ideal := 32 - 3*score
+	suitability := Suitability{
+		MinTemp: int(math.Max(lowestTemp, math.Round(ideal-comfortSpread))),
+		MaxTemp: int(math.Min(highestTemp, math.Round(ideal+comfortSpread))),
+	}
+	if garment.Category == taxonomy.Swimwear && suitability.MinTemp < 24 {
+		suitability.MinTemp = 24
+	}
+
+	for _, band := range bands {
+		low, high := max(band.min, suitability.MinTemp), min(band.max, suitability.MaxTemp)
+		if high-low >= minOverlap {
+			suitability.Weather = append(suitability.Weather, band.tag)
+		}
+	}
+	if taxonomy.ContainsWord(text, rainWords) {
+		suitability.Weather = append(suitability.Weather, Rain)
+	}
+	if blocksWind(garment, text) {
+		suitability.Weather = append(suitability.Weather, Wind)
+	}
+
+	suitability.Season = season(text, suitability.Weather)
+	return suitability
+}
+
+// neutral garments, such as bags and jewelry, suit any weather.
+func neutral(garment Garment) bool {
+	if garment.Category == taxonomy.Bag {
+		return true
+	}
+	if garment.Category != taxonomy.Accessory {
+		return false
+	}
+	switch garment.Subcategory {
+	case "hat", "scarf", "sunglasses":
+		return false
+	}
+	return true
+}
+
+// baseWarmth is the warmth of a typical garment of the category.
+func baseWarmth(garment Garment) float64 {
+	if warmth, ok := subcategoryWarmth[garment.Category+"/"+garment.Subcategory]; ok {
+		return warmth
+	}
+	if warmth, ok := categoryWarmth[garment.Category]; ok {
+		return warmth
+	}
+	return 3
+}
+
+var categoryWarmth = map[string]float64{
+	taxonomy.Shirt:     2,
+	taxonomy.Pants:     4,
+	taxonomy.Shorts:    1,
+	taxonomy.Skirt:     2,
+	taxonomy.Dress:     2,
+	taxonomy.Jacket:    7,
+	taxonomy.Swimwear:  0,
+	taxonomy.Underwear: 2,
+	taxonomy.Shoes:     3,
+	taxonomy.Accessory: 3,
+}
+
+var subcategoryWarmth = map[string]float64{
+	"shirt/tank-top":       1,
+	"shirt/cropped":        1,
+	"shirt/button-down":    3,
+	"pants/sweatpants":     5,
+	"skirt/long":           3,
+	"dress/long":           3,
+	"jacket/hoodie":        6,
+	"jacket/blazer":        5,
+	"jacket/denim":         5,
+	"jacket/knit":          6,
+	"jacket/vest":          5,
+	"jacket/coat":          8.5,
+	"jacket/puffer":        9,
+	"underwear/socks":      3,
+	"underwear/sleepwear":  3,
+	"shoes/sandals":        0,
+	"shoes/slippers":       1,
+	"shoes/boots":          7,
+	"accessory/hat":        1,
+	"accessory/scarf":      8,
+	"accessory/sunglasses": 0,
+}
+
+// materials maps a material to the change in warmth of a garment made
+// entirely of it.
+var materials = map[string]float64{
+	"linho":    -2,
+	"algodao":  -0.5,
+	"viscose":  -1,
+	"seda":     -1,
+	"liocel":   -1,
+	"lyocell":  -1,
+	"tencel":   -1,
+	"modal":    -0.5,
+	"cambraia": -1,
+	"voil":     -1.5,
+	"la":       3,
+	"merino":   3,
+	"cashmere": 3,
+	"caxemira": 3,
+	"alpaca":   3,
+	"fleece":   2.5,
+	"moletom":  2,
+	"pelucia":  3,
+	"sherpa":   3,
+	"trico":    2,
+	"veludo":   1.5,
+	"couro":    2,
+	"camurca":  1.5,
+	"acrilico": 1.5,
+	"jeans":    1,
+	"denim":    1,
+	"sarja":    0.5,
+}
+
+// materialWarmth weighs each material of a composition such as "70% lã, 30%
+// poliamida" by its share. Without percentages, the materials named anywhere
+// count evenly.
+func materialWarmth(garment Garment, text string) float64 {
+	composition := strings.ToLower(garment.Material + " " + garment.Description)
+	warmth, found := 0.0, false
+	for _, match := range compositionPattern.FindAllStringSubmatch(composition, -1) {
+		share, _ := strconv.Atoi(match[1])
+		for _, word := range strings.Fields(taxonomy.Normalize(match[2])) {
+			if material, ok := materials[word]; ok {
+				warmth += material * float64(share) / 100
+				found = true
+				break
+			}
+		}
+	}
+	if found {
+		return warmth
+	}
+
+	count := 0
+	seen := map[string]bool{}
+	for _, word := range strings.Fields(text) {
+		if material, ok := materials[word]; ok && !seen[word] {
+			seen[word] = true
+			warmth += material
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return warmth / float64(count)
+}
+
+type wordAdjustment struct {
+	words  []string
+	warmth float64
+}
+
+// adjustment returns the warmth of the first entry with a word in text.
+func adjustment(text string, adjustments []wordAdjustment) float64 {
+	for _, entry := range adjustments {
+		if taxonomy.ContainsWord(text, entry.words) {
+			return entry.warmth
+		}
+	}
+	return 0
+}
+
+var sleeveWarmth = []wordAdjustment{
+	{[]string{"sem manga", "alcinha", "alca fina", "tomara que caia"}, -1.5},
+	{[]string{"manga curta"}, -1},
+	{[]string{"manga 3 4"}, 0.5},
+	{[]string{"manga longa", "gola alta", "gola role"}, 1.5},
+}
+
+var seasonWarmth = []wordAdjustment{
+	{[]string{"inverno"}, 1},
+	{[]string{"verao"}, -0.5},
+}
+
+var (
+	lightWords = []string{"leve", "fino", "fina", "voil", "transparente", "fluido", "fluida"}
+	heavyWords = []string{
+		"grosso", "grossa", "pesado", "pesada", "encorpado", "encorpada", "forrado", "forrada",
+		"acolchoado", "acolchoada", "termico", "termica", "peluciado", "peluciada", "matelasse",
+	}
+)
+
+// fabricWeightWarmth reads the fabric weight in g/m² when given, and words
+// such as "leve" or "forrado" otherwise.
+func fabricWeightWarmth(garment Garment, text string) float64 {
+	raw := strings.ToLower(garment.Name + " " + garment.Description + " " + garment.Material)
+	if match := fabricWeightPattern.FindStringSubmatch(raw); match != nil {
+		grams, _ := strconv.Atoi(match[1])
+		switch {
+		case grams < 150:
+			return -1
+		case grams > 350:
+			return 2.5
+		case grams > 250:
+			return 1.5
+		}
+		return 0
+	}
+
+	light, heavy := taxonomy.ContainsWord(text, lightWords), taxonomy.ContainsWord(text, heavyWords)
+	switch {
+	case heavy && !light:
+		return 2
+	case light && !heavy:
+		return -1
+	}
+	return 0
+}
+
+var (
+	rainWords = []string{"impermeavel", "waterproof", "capa de chuva", "galocha", "a prova d agua", "hidrorrepelente"}
+	windWords = []string{"corta vento", "windbreaker", "anorak", "parka"}
+)
+
+// blocksWind is true for closed outerwear and for anything sold as a
+// windbreaker.
+func blocksWind(garment Garment, text string) bool {
+	if taxonomy.ContainsWord(text, windWords) {
+		return true
+	}
+	if garment.Category != taxonomy.Jacket {
+		return false
+	}
+	return garment.Subcategory == "coat" || garment.Subcategory == "puffer" ||
+		taxonomy.ContainsWord(text, []string{"nylon", "poliamida"})
+}
+
+var seasonWords = map[string]string{
+	"verao":     Summer,
+	"outono":    Autumn,
+	"inverno":   Winter,
+	"primavera": Spring,
+}
+
+// season uses the season named in the text when there is only one, as in
+// "Coleção Inverno" but not "Primavera-Verão", and the weather tags otherwise.
+func season(text string, weather []string) string {
+	named := ""
+	for word, season := range seasonWords {
+		if taxonomy.ContainsWord(text, []string{word}) {
+			if named != "" {
+				named = ""
+				break
+			}
+			named = season
+		}
+	}
+	if named != "" {
+		return named
+	}
+
+	has := map[string]bool{}
+	for _, tag := range weather {
+		has[tag] = true
+	}
+	switch {
+	case has[Hot] && !has[Mild] && !has[Cold]:
+		return Summer
+	case has[Cold] && !has[Warm] && !has[Hot]:
+		return Winter
+	}
+	return AllSeasons
+}
@@ -0,0 +1,97 @@
+package climate
+
+import (
+	"reflect"
+	"testing"
+
+	"shinewardrobe-scraper/internal/taxonomy"
+)
+
+func TestScoringModelAssess(t *testing.T) {
+	tests := []struct {
+		name    string
+		garment Garment
+		weather []string
+		season  string
+	}{
+		{
+			"linen shirt",
+			Garment{Category: taxonomy.Shirt, Subcategory: "button-down", Name: "Camisa Linho Manga Curta", Material: "100% linho"},
+			[]string{Hot, Warm}, Summer,
+		},
+		{
+			"cotton t-shirt",
+			Garment{Category: taxonomy.Shirt, Subcategory: "t-shirt", Name: "Camiseta Básica", Material: "100% algodão"},
+			[]string{Hot, Warm}, Summer,
+		},
+		{
+			"jeans",
+			Garment{Category: taxonomy.Pants, Subcategory: "jeans", Name: "Calça Jeans Reta"},
+			[]string{Warm, Mild}, AllSeasons,
+		},
+		{
+			"wool coat",
+			Garment{Category: taxonomy.Jacket, Subcategory: "coat", Name: "Casaco Lã Batida", Material: "70% lã, 30% poliéster"},
+			[]string{Cold, Wind}, Winter,
+		},
+		{
+			"fleece hoodie",
+			Garment{Category: taxonomy.Jacket, Subcategory: "hoodie", Name: "Blusa de Moletom Peluciado"},
+			[]string{Cold}, Winter,
+		},
+		{
+			"waterproof nylon jacket",
+			Garment{Category: taxonomy.Jacket, Subcategory: "casual", Name: "Jaqueta Corta-Vento Impermeável", Material: "100% nylon", Description: "Tecido leve."},
+			[]string{Mild, Cold, Rain, Wind}, Winter,
+		},
+		{
+			"long sleeve knit",
+			Garment{Category: taxonomy.Shirt, Subcategory: "long-sleeve", Name: "Blusa Manga Longa Tricô"},
+			[]string{Warm, Mild}, AllSeasons,
+		},
+		{
+			"heavy fabric weight",
+			Garment{Category: taxonomy.Shirt, Subcategory: "t-shirt", Name: "Camiseta Oversized", Description: "Malha 400 g/m²"},
+			[]string{Warm, Mild}, AllSeasons,
+		},
+		{
+			"bikini",
+			Garment{Category: taxonomy.Swimwear, Subcategory: "bikini", Name: "Biquíni Cortininha"},
+			[]string{Hot, Warm}, Summer,
+		},
+		{
+			"named season",
+			Garment{Category: taxonomy.Dress, Subcategory: "midi", Name: "Vestido Midi Coleção Outono"},
+			[]string{Hot, Warm}, Autumn,
+		},
+		{
+			"bag",
+			Garment{Category: taxonomy.Bag, Subcategory: "tote", Name: "Bolsa Sacola Couro"},
+			[]string{Hot, Warm, Mild, Cold}, AllSeasons,
+		},
+	}
+
+	model := ScoringModel{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := model.Assess(tt.garment)
+			if !reflect.DeepEqual(got.Weather, tt.weather) || got.Season != tt.season {
+				t.Errorf("Assess(%+v) = %+v, want weather %v and season %s", tt.garment, got, tt.weather, tt.season)
+			}
+			if got.MinTemp >= got.MaxTemp || got.MinTemp < lowestTemp || got.MaxTemp > highestTemp {
+				t.Errorf("Assess(%+v) gave the range %d..%d", tt.garment, got.MinTemp, got.MaxTemp)
+			}
+		})
+	}
+}
+
+func TestScoringModelOrdersByWarmth(t *testing.T) {
+	model := ScoringModel{}
+	lighter := model.Assess(Garment{Category: taxonomy.Shirt, Subcategory: "t-shirt", Name: "Camiseta Linho", Material: "100% linho"})
+	heavier := model.Assess(Garment{Category: taxonomy.Shirt, Subcategory: "t-shirt", Name: "Camiseta Lã Merino", Material: "100% lã"})
+	mixed := model.Assess(Garment{Category: taxonomy.Shirt, Subcategory: "t-shirt", Name: "Camiseta", Material: "50% linho, 50% lã"})
+
+	if !(lighter.MinTemp > mixed.MinTemp && mixed.MinTemp > heavier.MinTemp) {
+		t.Errorf("expected linen > blend > wool, got %d, %d and %d", lighter.MinTemp, mixed.MinTemp, heavier.MinTemp)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetEnvParsesValues(t *testing.T) {
+	t.Setenv("TEST_INT", "12")
+	t.Setenv("TEST_DURATION", "90s")
+	t.Setenv("TEST_BOOL", "off")
+
+	if got, err := getEnvInt("TEST_INT", 1); err != nil || got != 12 {
+		t.Errorf("getEnvInt = %d, %v, want 12", got, err)
+	}
+	if got, err := getEnvDuration("TEST_DURATION", time.Second); err != nil || got != 90*time.Second {
+		t.Errorf("getEnvDuration = %s, %v, want 1m30s", got, err)
+	}
+	if got, err := getEnvBool("TEST_BOOL", true); err != nil || got {
+		t.Errorf("getEnvBool = %v, %v, want false", got, err)
+	}
+
+	if got, err := getEnvInt("TEST_UNSET", 7); err != nil || got != 7 {
+		t.Errorf("getEnvInt of an unset variable = %d, %v, want the default 7", got, err)
+	}
+}
+
+func TestLoadRejectsMalformedVariables(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"SCRAPER_MAX_PRODUCTS", "fifty", `SCRAPER_MAX_PRODUCTS: expected an integer, got "fifty"`},
+		{"SCRAPER_CONCURRENCY", "2.5", `SCRAPER_CONCURRENCY: expected an integer, got "2.5"`},
+		{"SCRAPER_LOCK_WAIT", "30", `SCRAPER_LOCK_WAIT: expected a duration such as 30s or 5m, got "30"`},
+		{"SCRAPER_DETAIL_TIMEOUT", "15 minutes", `SCRAPER_DETAIL_TIMEOUT: expected a duration such as 30s or 5m, got "15 minutes"`},
+		{"CHROME_HEADLESS", "maybe", `CHROME_HEADLESS: expected true or false, got "maybe"`},
+		{"SCRAPER_INCLUDE_KIDS", "sim", `SCRAPER_INCLUDE_KIDS: expected true or false, got "sim"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryMalformedVariable(t *testing.T) {
+	t.Setenv("SCRAPER_MAX_PRODUCTS", "many")
+	t.Setenv("SCRAPER_DETAIL_ENABLED", "sometimes")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, key := range []string{"SCRAPER_MAX_PRODUCTS", "SCRAPER_DETAIL_ENABLED"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Load() error = %v, want it to name %s", err, key)
+		}
+	}
+}
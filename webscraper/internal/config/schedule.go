@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleParser parses schedules the way gocron's CronWithSeconds does.
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+var scheduleFields = []string{"second", "minute", "hour", "day of month", "month", "day of week"}
+
+// parseSchedule accepts six-field cron expressions starting with seconds,
+// standard five-field ones, which run at second 0, and descriptors such as
+// "@daily" or "@every 6h". It returns the schedule in a form accepted by
+// gocron's CronWithSeconds.
+func parseSchedule(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("must not be empty")
+	}
+	if strings.HasPrefix(value, "TZ=") || strings.HasPrefix(value, "CRON_TZ=") {
+		return "", fmt.Errorf("must not set a time zone, use SCRAPER_TIMEZONE instead: %q", value)
+	}
+
+	if strings.HasPrefix(value, "@") {
+		if _, err := scheduleParser.Parse(value); err != nil {
+			return "", fmt.Errorf("invalid descriptor %q: %w", value, err)
+		}
+		return value, nil
+	}
+
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return "", fmt.Errorf("expected 6 fields (second minute hour day-of-month month day-of-week), 5 without seconds or a descriptor such as @daily, got %d in %q", len(fields), value)
+	}
+
+	// Each field is checked on its own so the error names it.
+	for i, field := range fields {
+		probe := []string{"*", "*", "*", "*", "*", "*"}
+		probe[i] = field
+		if _, err := scheduleParser.Parse(strings.Join(probe, " ")); err != nil {
+			return "", fmt.Errorf("invalid %s field %q in %q: %w", scheduleFields[i], field, value, err)
+		}
+	}
+
+	return strings.Join(fields, " "), nil
+}
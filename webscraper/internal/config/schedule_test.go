@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"0 59 23 * * *", "0 59 23 * * *"},
+		{"59 23 * * *", "0 59 23 * * *"},
+		{"  */30 * * * * 1-5 ", "*/30 * * * * 1-5"},
+		{"@daily", "@daily"},
+		{"@every 6h", "@every 6h"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSchedule(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSchedule(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"daily", "expected 6 fields"},
+		{"0 0 * *", "got 4"},
+		{"0 61 23 * * *", "invalid minute field \"61\""},
+		{"0 0 25 * * *", "invalid hour field \"25\""},
+		{"0 0 0 * * funday", "invalid day of week field"},
+		{"@every six hours", "invalid descriptor"},
+		{"@fortnightly", "invalid descriptor"},
+		{"CRON_TZ=UTC 0 0 * * *", "SCRAPER_TIMEZONE"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseSchedule(tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseSchedule(%q) error = %v, want it to mention %q", tt.value, err, tt.want)
+		}
+	}
+}
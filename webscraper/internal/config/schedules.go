@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultJobTimeout bounds a scheduled scrape when no timeout is configured.
+const DefaultJobTimeout = 30 * time.Minute
+
+// Schedules sets when each site is scraped. Sites missing from Sites follow
+// Default, whose schedule is SCRAPER_SCHEDULE unless the file sets one.
+type Schedules struct {
+	Default JobSchedule             `yaml:"default"`
+	Sites   map[string]SiteSchedule `yaml:"sites"`
+}
+
+// JobSchedule configures one scheduled job. Empty fields are inherited: a
+// site from Default and a category from its site.
+type JobSchedule struct {
+	Enabled  *bool         `yaml:"enabled"`
+	Schedule string        `yaml:"schedule"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// SiteSchedule configures the job of a site, keyed by its source. Categories
+// listed here get jobs of their own on top of the site job, for prices that
+// move faster than the rest of the catalog.
+type SiteSchedule struct {
+	JobSchedule `yaml:",inline"`
+	Categories  map[string]JobSchedule `yaml:"categories"`
+}
+
+// IsEnabled reports whether the job runs, which it does unless disabled.
+func (j JobSchedule) IsEnabled() bool {
+	return j.Enabled == nil || *j.Enabled
+}
+
+// inherit fills the empty fields of j from parent.
+func (j JobSchedule) inherit(parent JobSchedule) JobSchedule {
+	if j.Enabled == nil {
+		j.Enabled = parent.Enabled
+	}
+	if j.Schedule == "" {
+		j.Schedule = parent.Schedule
+	}
+	if j.Timeout == 0 {
+		j.Timeout = parent.Timeout
+	}
+	return j
+}
+
+// Site returns the job of the site with source and the jobs of its
+// categories, with inherited fields filled in.
+func (s *Schedules) Site(source string) (JobSchedule, map[string]JobSchedule) {
+	site := s.Sites[source]
+	job := site.JobSchedule.inherit(s.Default)
+
+	categories := map[string]JobSchedule{}
+	for name, category := range site.Categories {
+		categories[name] = category.inherit(job)
+	}
+	return job, categories
+}
+
+// loadSchedules reads the schedules in path, or returns an empty set when
+// path is empty, and fills the default schedule and timeout.
+func loadSchedules(path, defaultSchedule string) (*Schedules, error) {
+	schedules := &Schedules{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schedules: %w", err)
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(schedules); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: failed to decode: %w", path, err)
+		}
+	}
+
+	if schedules.Default.Schedule == "" {
+		schedules.Default.Schedule = defaultSchedule
+	}
+	if schedules.Default.Timeout == 0 {
+		schedules.Default.Timeout = DefaultJobTimeout
+	}
+
+	if err := schedules.normalize(); err != nil {
+		if path != "" {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// normalize checks every schedule and rewrites it in the six-field form.
+func (s *Schedules) normalize() error {
+	var errs []error
+	check := func(field string, job *JobSchedule) {
+		if job.Schedule != "" {
+			schedule, err := parseSchedule(job.Schedule)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.schedule: %w", field, err))
+			}
+			job.Schedule = schedule
+		}
+		if job.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s.timeout: must not be negative, got %s", field, job.Timeout))
+		}
+	}
+
+	check("default", &s.Default)
+	for _, source := range sortedKeys(s.Sites) {
+		site := s.Sites[source]
+		check("sites."+source, &site.JobSchedule)
+		for _, name := range sortedKeys(site.Categories) {
+			category := site.Categories[name]
+			field := "sites." + source + ".categories." + name
+			if category.Schedule == "" {
+				errs = append(errs, fmt.Errorf("%s.schedule: is required", field))
+			}
+			check(field, &category)
+			site.Categories[name] = category
+		}
+		s.Sites[source] = site
+	}
+
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSchedules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schedules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSchedulesInherits(t *testing.T) {
+	path := writeSchedules(t, `
+default:
+  timeout: 20m
+sites:
+  americanas:
+    schedule: "@every 1h"
+    timeout: 10m
+    categories:
+      camisetas-masculino:
+        schedule: "*/15 * * * *"
+  zara:
+    schedule: "0 0 3 * * 1"
+  renner:
+    enabled: false
+`)
+
+	schedules, err := loadSchedules(path, "0 59 23 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site, categories := schedules.Site("americanas")
+	if site.Schedule != "@every 1h" || site.Timeout != 10*time.Minute || !site.IsEnabled() {
+		t.Errorf("americanas = %+v", site)
+	}
+	category := categories["camisetas-masculino"]
+	if category.Schedule != "0 */15 * * * *" || category.Timeout != 10*time.Minute {
+		t.Errorf("americanas/camisetas-masculino = %+v, want the site timeout", category)
+	}
+
+	if site, _ := schedules.Site("ca"); site.Schedule != "0 59 23 * * *" || site.Timeout != 20*time.Minute {
+		t.Errorf("ca = %+v, want the default schedule", site)
+	}
+	if site, _ := schedules.Site("renner"); site.IsEnabled() {
+		t.Error("renner should be disabled")
+	}
+}
+
+func TestLoadSchedulesWithoutFile(t *testing.T) {
+	schedules, err := loadSchedules("", "@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site, _ := schedules.Site("zara"); site.Schedule != "@daily" || site.Timeout != DefaultJobTimeout {
+		t.Errorf("zara = %+v", site)
+	}
+}
+
+func TestLoadSchedulesErrors(t *testing.T) {
+	path := writeSchedules(t, `
+sites:
+  zara:
+    schedule: "0 0 25 * * *"
+    categories:
+      vestidos:
+        timeout: 5m
+`)
+
+	_, err := loadSchedules(path, "0 59 23 * * *")
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		`sites.zara.schedule: invalid hour field "25"`,
+		"sites.zara.categories.vestidos.schedule: is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestExampleSchedulesLoad(t *testing.T) {
+	if _, err := loadSchedules(filepath.Join("..", "..", "schedules.example.yaml"), "0 59 23 * * *"); err != nil {
+		t.Fatal(err)
+	}
+}
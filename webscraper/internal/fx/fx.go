@@ -0,0 +1,102 @@
+// Package fx converts prices between currencies.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrUnknownCurrency is returned when a provider has no rate for a currency.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Provider returns how many units of to one unit of from is worth.
+type Provider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// StaticProvider serves fixed rates, for offline use and tests.
+type StaticProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticProvider builds a provider from the value of one unit of each
+// currency in base, e.g. base "BRL" and {"USD": 5.05}.
+func NewStaticProvider(base string, rates map[string]float64) (*StaticProvider, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	if !currencyCodePattern.MatchString(base) {
+		return nil, fmt.Errorf("invalid base currency %q", base)
+	}
+
+	provider := &StaticProvider{base: base, rates: map[string]float64{base: 1}}
+	for currency, rate := range rates {
+		code := strings.ToUpper(strings.TrimSpace(currency))
+		if !currencyCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid currency %q", currency)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be positive, got %v", code, rate)
+		}
+		provider.rates[code] = rate
+	}
+	return provider, nil
+}
+
+// LoadStaticFile reads rates from a JSON file such as
+//
+//	{"base": "BRL", "rates": {"USD": 5.05, "EUR": 5.45}}
+func LoadStaticFile(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FX rates: %w", err)
+	}
+
+	var file struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse FX rates %s: %w", path, err)
+	}
+
+	provider, err := NewStaticProvider(file.Base, file.Rates)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FX rates %s: %w", path, err)
+	}
+	return provider, nil
+}
+
+func (p *StaticProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	fromRate, ok := p.rates[strings.ToUpper(from)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := p.rates[strings.ToUpper(to)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+	return fromRate / toRate, nil
+}
+
+// Convert converts amount from one currency to another through provider.
+func Convert(ctx context.Context, provider Provider, amount float64, from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+	if provider == nil {
+		return 0, fmt.Errorf("no FX rates to convert %s to %s", from, to)
+	}
+
+	rate, err := provider.Rate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
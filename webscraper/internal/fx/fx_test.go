@@ -0,0 +1,70 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	provider, err := NewStaticProvider("BRL", map[string]float64{"USD": 5, "EUR": 5.5})
+	if err != nil {
+		t.Fatalf("NewStaticProvider: %v", err)
+	}
+
+	tests := []struct {
+		amount   float64
+		from, to string
+		want     float64
+	}{
+		{100, "BRL", "BRL", 100},
+		{20, "USD", "BRL", 100},
+		{100, "BRL", "USD", 20},
+		{10, "eur", "usd", 11},
+	}
+
+	for _, tt := range tests {
+		got, err := Convert(context.Background(), provider, tt.amount, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("Convert(%v, %s, %s): %v", tt.amount, tt.from, tt.to, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.amount, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := Convert(context.Background(), provider, 10, "GBP", "BRL"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Convert(GBP) error = %v, want ErrUnknownCurrency", err)
+	}
+	if _, err := Convert(context.Background(), nil, 10, "USD", "BRL"); err == nil {
+		t.Error("Convert without a provider should fail for different currencies")
+	}
+	if got, err := Convert(context.Background(), nil, 10, "BRL", "BRL"); err != nil || got != 10 {
+		t.Errorf("Convert without a provider for the same currency = %v, %v", got, err)
+	}
+}
+
+func TestLoadStaticFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base": "BRL", "rates": {"USD": 5.05}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := LoadStaticFile(path)
+	if err != nil {
+		t.Fatalf("LoadStaticFile: %v", err)
+	}
+	if rate, err := provider.Rate(context.Background(), "USD", "BRL"); err != nil || rate != 5.05 {
+		t.Errorf("Rate(USD, BRL) = %v, %v, want 5.05", rate, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"base": "BRL", "rates": {"USD": -1}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadStaticFile(path); err == nil {
+		t.Error("LoadStaticFile should reject non-positive rates")
+	}
+}
@@ -0,0 +1,335 @@
+// Package pricing parses the price text shown by stores, such as
+// "De R$ 199,90 por R$ 149,90" or "10x de R$ 29,90 sem juros".
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Currency string
+
+const (
+	BRL Currency = "BRL"
+	USD Currency = "USD"
+)
+
+var (
+	// ErrEmpty is returned for blank input.
+	ErrEmpty = errors.New("empty price text")
+	// ErrNoAmount is returned when the text has no monetary amount.
+	ErrNoAmount = errors.New("no amount found")
+	// ErrInvalidAmount is returned for amounts whose separators do not form
+	// a valid number, such as "1.2.3" or "12,345,6".
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrNonPositive is returned when the current price is zero.
+	ErrNonPositive = errors.New("price must be positive")
+)
+
+// ParseError reports the input that could not be parsed. Use errors.Is with
+// the Err* values to tell the causes apart.
+type ParseError struct {
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse price %q: %v", e.Input, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Installments is a payment plan such as "10x de R$ 29,90 sem juros".
+type Installments struct {
+	Count        int
+	Value        float64
+	InterestFree bool
+}
+
+// Price is the structured result of Parse. Original is zero when no previous
+// price was shown, Max is zero unless the text is a range, Cash is zero unless
+// a Pix, boleto or "à vista" price was shown, and Installments is nil when no
+// plan was shown.
+type Price struct {
+	Current      float64
+	Original     float64
+	Max          float64
+	Cash         float64
+	Currency     Currency
+	Installments *Installments
+}
+
+var (
+	amountPattern      = regexp.MustCompile(`\d(?:[\d.,]*\d)?`)
+	currencyPattern    = regexp.MustCompile(`(r\$|us\$|u\$s|\$|brl|usd)\s*$`)
+	installmentPattern = regexp.MustCompile(`(\d{1,2})\s*x\s*(?:de\s*)?((?:r\$|us\$|\$|brl|usd)?\s*\d(?:[\d.,]*\d)?)(\s*(?:sem|s/)\s*juros)?`)
+	rangePattern       = regexp.MustCompile(`^\s*(?:-|–|a|até|ate|to)\s*(?:r\$|us\$|\$|brl|usd)?\s*$`)
+	originalPattern    = regexp.MustCompile(`(?:^|\s)(?:de|antes|was)\s*:?\s*(?:r\$|us\$|\$|brl|usd)?\s*$`)
+	currentPattern     = regexp.MustCompile(`(?:^|\s)(?:por|agora|now)\s*:?\s*(?:r\$|us\$|\$|brl|usd)?\s*$`)
+	cashSuffixPattern  = regexp.MustCompile(`^\s*(?:(?:à|a) vista|(?:(?:à|a) vista\s*)?(?:no|na|via|pelo|com|pagando (?:no|com))?\s*(?:pix|boleto))`)
+	cashPrefixPattern  = regexp.MustCompile(`(?:pix|boleto|(?:à|a) vista)\s*:?\s*(?:(?:por|de)\s*)?(?:r\$|us\$|\$|brl|usd)?\s*$`)
+	cashPercentPattern = regexp.MustCompile(`(\d{1,2}(?:[.,]\d{1,2})?)\s*%\s*(?:de\s*)?(?:desconto|off|desc\.?)?\s*(?:(?:à|a) vista\s*)?(?:no|na|via|pelo|com|pagando (?:no|com))?\s*(?:pix|boleto)`)
+)
+
+type amount struct {
+	value    float64
+	currency Currency
+	symbol   bool
+	start    int
+	end      int
+}
+
+// Parse reads a price shown in Brazilian Reais unless the text names another
+// currency.
+func Parse(text string) (Price, error) {
+	return ParseCurrency(text, BRL)
+}
+
+// ParseCurrency reads a price, assuming currency when the text has no
+// currency symbol. The currency also decides the decimal separator of
+// ambiguous amounts: "1.299" is 1299 in BRL and 1.299 in USD.
+func ParseCurrency(text string, currency Currency) (Price, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(text, "\u00a0", " ")), " "))
+	if normalized == "" {
+		return Price{}, &ParseError{Input: text, Err: ErrEmpty}
+	}
+
+	price := Price{Currency: detectCurrency(normalized, currency)}
+
+	if match := installmentPattern.FindStringSubmatchIndex(normalized); match != nil {
+		count, _ := strconv.Atoi(normalized[match[2]:match[3]])
+		valueText := normalized[match[4]:match[5]]
+		value, err := parseAmount(amountPattern.FindString(valueText), price.Currency)
+		if err != nil {
+			return Price{}, &ParseError{Input: text, Err: err}
+		}
+		if count > 0 && value > 0 {
+			price.Installments = &Installments{Count: count, Value: value, InterestFree: match[6] >= 0}
+		}
+		normalized = normalized[:match[0]] + strings.Repeat(" ", match[1]-match[0]) + normalized[match[1]:]
+	}
+
+	amounts, err := findAmounts(normalized, price.Currency)
+	if err != nil {
+		return Price{}, &ParseError{Input: text, Err: err}
+	}
+
+	listed := amounts[:0:0]
+	for _, a := range amounts {
+		if cashSuffixPattern.MatchString(normalized[a.end:]) || cashPrefixPattern.MatchString(normalized[:a.start]) {
+			if price.Cash == 0 || a.value < price.Cash {
+				price.Cash = a.value
+			}
+			continue
+		}
+		listed = append(listed, a)
+	}
+	amounts = listed
+
+	switch {
+	case len(amounts) == 0 && price.Cash > 0:
+		price.Current = price.Cash
+	case len(amounts) == 0 && price.Installments != nil:
+		// Only the plan is shown: the price is what the plan adds up to.
+		price.Current = round(float64(price.Installments.Count) * price.Installments.Value)
+	case len(amounts) == 0:
+		return Price{}, &ParseError{Input: text, Err: ErrNoAmount}
+	case len(amounts) >= 2 && rangePattern.MatchString(normalized[amounts[0].end:amounts[1].start]):
+		price.Current, price.Max = amounts[0].value, amounts[1].value
+		if price.Max < price.Current {
+			price.Current, price.Max = price.Max, price.Current
+		}
+	default:
+		price.Current, price.Original = pickCurrent(normalized, amounts)
+	}
+
+	if price.Current <= 0 {
+		return Price{}, &ParseError{Input: text, Err: ErrNonPositive}
+	}
+	if price.Original <= price.Current {
+		price.Original = 0
+	}
+
+	// "5% de desconto no Pix" gives the cash price relative to the current one.
+	if match := cashPercentPattern.FindStringSubmatch(normalized); match != nil && price.Cash == 0 {
+		if percent, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64); err == nil && percent > 0 && percent < 100 {
+			price.Cash = round(price.Current * (1 - percent/100))
+		}
+	}
+	if price.Cash >= price.Current && len(amounts) > 0 {
+		price.Cash = 0
+	}
+	return price, nil
+}
+
+// pickCurrent uses "de"/"por" markers when present. Otherwise the lowest
+// amount is the current price and the highest one, if different, the original.
+func pickCurrent(text string, amounts []amount) (float64, float64) {
+	var current, original float64
+	for _, a := range amounts {
+		prefix := text[:a.start]
+		switch {
+		case current == 0 && currentPattern.MatchString(prefix):
+			current = a.value
+		case original == 0 && originalPattern.MatchString(prefix):
+			original = a.value
+		}
+	}
+
+	values := make([]float64, len(amounts))
+	for i, a := range amounts {
+		values[i] = a.value
+	}
+	sort.Float64s(values)
+
+	if current == 0 {
+		current = values[0]
+	}
+	if original == 0 {
+		original = values[len(values)-1]
+	}
+	return current, original
+}
+
+func findAmounts(text string, currency Currency) ([]amount, error) {
+	var candidates []amount
+	for _, match := range amountPattern.FindAllStringIndex(text, -1) {
+		candidate := amount{currency: currency, start: match[0], end: match[1]}
+		if symbol := currencyPattern.FindString(text[:match[0]]); symbol != "" {
+			candidate.currency = symbolCurrency(strings.TrimSpace(symbol), currency)
+			candidate.symbol = true
+		} else if percentFollows(text, match[1]) {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Bare numbers next to amounts with a currency symbol are quantities or
+	// sizes, as in "Kit 2 peças R$ 49,90".
+	withSymbol := candidates[:0:0]
+	for _, candidate := range candidates {
+		if candidate.symbol {
+			withSymbol = append(withSymbol, candidate)
+		}
+	}
+	if len(withSymbol) > 0 {
+		candidates = withSymbol
+	}
+
+	for i := range candidates {
+		value, err := parseAmount(text[candidates[i].start:candidates[i].end], candidates[i].currency)
+		if err != nil {
+			return nil, err
+		}
+		candidates[i].value = value
+	}
+	return candidates, nil
+}
+
+// percentFollows skips discount badges such as "30% off".
+func percentFollows(text string, end int) bool {
+	return strings.HasPrefix(strings.TrimLeft(text[end:], " "), "%")
+}
+
+func detectCurrency(text string, fallback Currency) Currency {
+	switch {
+	case strings.Contains(text, "r$") || strings.Contains(text, "brl"):
+		return BRL
+	case strings.Contains(text, "us$") || strings.Contains(text, "u$s") || strings.Contains(text, "usd") || strings.Contains(text, "$"):
+		return USD
+	}
+	return fallback
+}
+
+func symbolCurrency(symbol string, fallback Currency) Currency {
+	switch symbol {
+	case "r$", "brl":
+		return BRL
+	case "us$", "u$s", "usd", "$":
+		return USD
+	}
+	return fallback
+}
+
+// parseAmount converts digits with thousands and decimal separators. When the
+// text uses a single separator once, it is a decimal separator if one or two
+// digits follow it and a thousands separator if three do.
+func parseAmount(raw string, currency Currency) (float64, error) {
+	if raw == "" {
+		return 0, ErrNoAmount
+	}
+
+	decimalSeparator, thousandsSeparator := ",", "."
+	if currency == USD {
+		decimalSeparator, thousandsSeparator = ".", ","
+	}
+
+	lastDot, lastComma := strings.LastIndex(raw, "."), strings.LastIndex(raw, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Both separators: the last one is the decimal separator.
+		if lastDot > lastComma {
+			decimalSeparator, thousandsSeparator = ".", ","
+		} else {
+			decimalSeparator, thousandsSeparator = ",", "."
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		separator := "."
+		if lastComma >= 0 {
+			separator = ","
+		}
+		digitsAfter := len(raw) - strings.LastIndex(raw, separator) - 1
+		switch {
+		case strings.Count(raw, separator) > 1:
+			decimalSeparator, thousandsSeparator = otherSeparator(separator), separator
+		case digitsAfter == 3 && separator == thousandsSeparator:
+		case digitsAfter <= 2:
+			decimalSeparator, thousandsSeparator = separator, otherSeparator(separator)
+		default:
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	integer, fraction := raw, ""
+	if index := strings.LastIndex(raw, decimalSeparator); index >= 0 {
+		integer, fraction = raw[:index], raw[index+1:]
+		if len(fraction) == 0 || len(fraction) > 2 || strings.ContainsAny(fraction, ".,") {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	groups := strings.Split(integer, thousandsSeparator)
+	for i, group := range groups {
+		if group == "" || strings.ContainsAny(group, ".,") || (i > 0 && len(group) != 3) || (i == 0 && len(groups) > 1 && len(group) > 3) {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	number := strings.Join(groups, "")
+	if fraction != "" {
+		number += "." + fraction
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	return value, nil
+}
+
+func otherSeparator(separator string) string {
+	if separator == "." {
+		return ","
+	}
+	return "."
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
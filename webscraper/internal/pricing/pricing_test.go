@@ -0,0 +1,124 @@
+package pricing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Price
+	}{
+		{"simple", "R$ 49,90", Price{Current: 49.9, Currency: BRL}},
+		{"thousands", "R$ 1.299,90", Price{Current: 1299.9, Currency: BRL}},
+		{"thousands without cents", "R$ 1.299", Price{Current: 1299, Currency: BRL}},
+		{"millions", "R$ 1.234.567,89", Price{Current: 1234567.89, Currency: BRL}},
+		{"no symbol", "89,90", Price{Current: 89.9, Currency: BRL}},
+		{"dot decimal", "R$ 49.90", Price{Current: 49.9, Currency: BRL}},
+		{"non-breaking space", "R$\u00a0159,90", Price{Current: 159.9, Currency: BRL}},
+		{"starting at", "a partir de R$ 49,90", Price{Current: 49.9, Currency: BRL}},
+		{"de por", "De R$ 199,90 por R$ 149,90", Price{Current: 149.9, Original: 199.9, Currency: BRL}},
+		{"de por with colons", "De: R$ 89,90 Por: R$ 59,90", Price{Current: 59.9, Original: 89.9, Currency: BRL}},
+		{"two prices without markers", "R$ 189,90 R$ 159,90", Price{Current: 159.9, Original: 189.9, Currency: BRL}},
+		{"range with dash", "R$ 49,90 - R$ 79,90", Price{Current: 49.9, Max: 79.9, Currency: BRL}},
+		{"range with a", "R$ 79,90 a R$ 49,90", Price{Current: 49.9, Max: 79.9, Currency: BRL}},
+		{"discount badge", "30% OFF R$ 69,90", Price{Current: 69.9, Currency: BRL}},
+		{"quantity in text", "Kit 2 peças R$ 99,90", Price{Current: 99.9, Currency: BRL}},
+		{
+			"price and installments",
+			"R$ 299,00 ou 10x de R$ 29,90 sem juros",
+			Price{Current: 299, Currency: BRL, Installments: &Installments{Count: 10, Value: 29.9, InterestFree: true}},
+		},
+		{
+			"installments only",
+			"10x R$ 12,90",
+			Price{Current: 129, Currency: BRL, Installments: &Installments{Count: 10, Value: 12.9}},
+		},
+		{
+			"de por with installments",
+			"de R$ 399,90 por R$ 299,90 em até 5x de R$ 59,98 s/ juros",
+			Price{Current: 299.9, Original: 399.9, Currency: BRL, Installments: &Installments{Count: 5, Value: 59.98, InterestFree: true}},
+		},
+		{"pix price", "R$ 159,90 ou R$ 151,90 no Pix", Price{Current: 159.9, Cash: 151.9, Currency: BRL}},
+		{"pix prefix", "Pix: R$ 94,90 | Cartão: R$ 99,90", Price{Current: 99.9, Cash: 94.9, Currency: BRL}},
+		{"a vista", "R$ 89,90 à vista", Price{Current: 89.9, Cash: 89.9, Currency: BRL}},
+		{"pix percentage", "R$ 200,00 com 5% de desconto no Pix", Price{Current: 200, Cash: 190, Currency: BRL}},
+		{
+			"de por pix and installments",
+			"De R$ 249,90 Por R$ 199,90 ou R$ 189,90 no boleto 6x de R$ 33,32 sem juros",
+			Price{Current: 199.9, Original: 249.9, Cash: 189.9, Currency: BRL, Installments: &Installments{Count: 6, Value: 33.32, InterestFree: true}},
+		},
+		{"usd", "$1,299.99", Price{Current: 1299.99, Currency: USD}},
+		{"usd thousands without cents", "US$ 1,299", Price{Current: 1299, Currency: USD}},
+		{"usd was now", "Was $59.99 Now $39.99", Price{Current: 39.99, Original: 59.99, Currency: USD}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v (installments %+v), want %+v (installments %+v)", tt.text, got, got.Installments, tt.want, tt.want.Installments)
+			}
+		})
+	}
+}
+
+func TestParseCurrencyDecidesAmbiguousSeparators(t *testing.T) {
+	tests := []struct {
+		text     string
+		currency Currency
+		want     float64
+	}{
+		{"1.299", BRL, 1299},
+		{"1,299", USD, 1299},
+		{"12.50", USD, 12.5},
+		{"12,50", BRL, 12.5},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCurrency(tt.text, tt.currency)
+		if err != nil {
+			t.Fatalf("ParseCurrency(%q, %s): %v", tt.text, tt.currency, err)
+		}
+		if got.Current != tt.want || got.Currency != tt.currency {
+			t.Errorf("ParseCurrency(%q, %s) = %+v, want %v %s", tt.text, tt.currency, got, tt.want, tt.currency)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"empty", "", ErrEmpty},
+		{"blank", "   ", ErrEmpty},
+		{"unavailable", "Indisponível", ErrNoAmount},
+		{"only symbol", "R$", ErrNoAmount},
+		{"zero", "R$ 0,00", ErrNonPositive},
+		{"too many decimals", "R$ 12,3456", ErrInvalidAmount},
+		{"bad grouping", "R$ 12.34.5", ErrInvalidAmount},
+		{"comma thousands in brl", "R$ 1,299", ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.text, err, tt.want)
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) || parseErr.Input != tt.text {
+				t.Errorf("Parse(%q) error = %#v, want *ParseError with the input", tt.text, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Package runs records the history of scrape runs in the scrape_runs table,
+// one row per site scraped, so freshness and failures can be looked up
+// without the logs.
+package runs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// What started a run.
+const (
+	TriggerInitial   = "initial"
+	TriggerScheduled = "scheduled"
+	TriggerManual    = "manual"
+)
+
+// Statuses of a site run.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusPartial   = "partial"
+	StatusFailed    = "failed"
+	// StatusSkipped is used when another run was scraping the site.
+	StatusSkipped = "skipped"
+)
+
+// Run is the scrape of one site within a run. Sites scraped by the same run
+// share RunID, a UUID.
+type Run struct {
+	ID      string
+	RunID   string
+	Trigger string
+	Source  string
+	// Categories is set when only some categories were scraped.
+	Categories []string
+	Status     string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+
+	// Found counts the items the site listed and Rejected the ones not
+	// saved: items without name or price, repeated products and children's
+	// clothing. Found = Inserted + Updated + Rejected.
+	Found       int
+	Inserted    int
+	Updated     int
+	Rejected    int
+	Unavailable int
+
+	Error string
+	// Config is a snapshot of the settings the run used.
+	Config json.RawMessage
+}
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Start inserts run with the running status and sets its ID.
+func (r *Repository) Start(ctx context.Context, run *Run) error {
+	run.Status = StatusRunning
+	categories, err := nullJSON(run.Categories)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO scrape_runs (run_id, trigger, source, categories, status, started_at, config)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		run.RunID, run.Trigger, run.Source, categories, run.Status, run.StartedAt, nullRaw(run.Config),
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record start of %s run: %w", run.Source, err)
+	}
+	return nil
+}
+
+// Finish stores the outcome of a started run.
+func (r *Repository) Finish(ctx context.Context, run *Run) error {
+	if run.FinishedAt == nil {
+		now := time.Now()
+		run.FinishedAt = &now
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE scrape_runs SET
+			status = $2, finished_at = $3,
+			products_found = $4, products_inserted = $5, products_updated = $6,
+			products_rejected = $7, products_unavailable = $8, error = $9
+		WHERE id = $1`,
+		run.ID, run.Status, run.FinishedAt,
+		run.Found, run.Inserted, run.Updated, run.Rejected, run.Unavailable, nullString(run.Error),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record end of %s run: %w", run.Source, err)
+	}
+	return nil
+}
+
+// Latest returns the most recent run of every source, skipped runs aside.
+func (r *Repository) Latest(ctx context.Context) ([]Run, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (source)
+			id, run_id, trigger, source, categories, status, started_at, finished_at,
+			products_found, products_inserted, products_updated, products_rejected,
+			products_unavailable, COALESCE(error, ''), config
+		FROM scrape_runs
+		WHERE status <> $1
+		ORDER BY source, started_at DESC`, StatusSkipped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest runs: %w", err)
+	}
+	defer rows.Close()
+
+	var latest []Run
+	for rows.Next() {
+		var run Run
+		var categories, config []byte
+		err := rows.Scan(
+			&run.ID, &run.RunID, &run.Trigger, &run.Source, &categories, &run.Status, &run.StartedAt, &run.FinishedAt,
+			&run.Found, &run.Inserted, &run.Updated, &run.Rejected, &run.Unavailable, &run.Error, &config,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		if categories != nil {
+			if err := json.Unmarshal(categories, &run.Categories); err != nil {
+				return nil, fmt.Errorf("failed to decode categories of run %s: %w", run.ID, err)
+			}
+		}
+		run.Config = config
+		latest = append(latest, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read latest runs: %w", err)
+	}
+	return latest, nil
+}
+
+func nullJSON(values []string) (interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode categories: %w", err)
+	}
+	return string(data), nil
+}
+
+func nullRaw(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
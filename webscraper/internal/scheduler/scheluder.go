@@ -2,7 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/runs"
 	"shinewardrobe-scraper/internal/scraper"
 
 	"time"
@@ -12,44 +16,66 @@ import (
 )
 
 type Service struct {
-	scraper  *scraper.Service
-	schedule string
-	logger   *logrus.Entry
-	cron     *gocron.Scheduler
+	scraper   *scraper.Service
+	schedules *config.Schedules
+	logger    *logrus.Entry
+	cron      *gocron.Scheduler
 }
 
-func NewService(scraperService *scraper.Service, schedule string, logger *logrus.Entry) *Service {
+// job scrapes a site, or some of its categories, on its own schedule.
+type job struct {
+	name       string
+	source     string
+	categories []string
+	schedule   string
+	timeout    time.Duration
+}
+
+// NewService registers a job per site, and per category given a schedule of
+// its own. Schedules are six-field cron expressions with seconds or
+// descriptors such as "@every 6h", evaluated in location.
+func NewService(scraperService *scraper.Service, schedules *config.Schedules, location *time.Location, logger *logrus.Entry) *Service {
 	return &Service{
-		scraper:  scraperService,
-		schedule: schedule,
-		logger:   logger.WithField("component", "scheduler"),
-		cron:     gocron.NewScheduler(time.UTC),
+		scraper:   scraperService,
+		schedules: schedules,
+		logger:    logger.WithFields(logrus.Fields{"component": "scheduler", "timezone": location.String()}),
+		cron:      gocron.NewScheduler(location),
 	}
 }
 
-func (s *Service) Start(ctx context.Context) {
-	s.logger.WithField("schedule", s.schedule).Info("Starting scheduler...")
-	cronFields := parseCronSchedule(s.schedule)
-	
-	_, err := s.cron.Cron(cronFields).Do(func() {
-		s.logger.Info("Executing scheduled scraping job...")
-		
-		jobCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
-		defer cancel()
-		
-		if err := s.scraper.ScrapeAll(jobCtx); err != nil {
-			s.logger.WithError(err).Error("Scheduled scraping job failed")
-		} else {
-			s.logger.Info("Scheduled scraping job completed successfully")
-		}
-	})
-
+func (s *Service) Start(ctx context.Context) error {
+	jobs, err := s.jobs()
 	if err != nil {
-		s.logger.WithError(err).Fatal("Failed to schedule scraping job")
+		return err
+	}
+	s.logger.WithField("jobs", len(jobs)).Info("Starting scheduler...")
+
+	// In singleton mode a run that comes due while the previous one is still
+	// going waits for it. Runs of other jobs on the same site, the initial
+	// scrape and other replicas are kept apart by the site locks of the
+	// scraper service.
+	scheduled := make([]*gocron.Job, len(jobs))
+	for i, job := range jobs {
+		i, job := i, job
+		scheduled[i], err = s.cron.CronWithSeconds(job.schedule).Tag(job.name).SingletonMode().Do(func() {
+			s.run(ctx, job, scheduled[i])
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule %s with %q: %w", job.name, job.schedule, err)
+		}
+		s.logger.WithFields(logrus.Fields{
+			"job":      job.name,
+			"schedule": job.schedule,
+			"timeout":  job.timeout.String(),
+		}).Info("Scraping job scheduled")
 	}
 
 	s.cron.StartAsync()
+	for i, job := range jobs {
+		s.logger.WithFields(logrus.Fields{"job": job.name, "next_run": scheduled[i].NextRun()}).Info("Next scraping run")
+	}
 	s.logger.Info("Scheduler started successfully")
+	return nil
 }
 
 func (s *Service) Stop() {
@@ -58,25 +84,75 @@ func (s *Service) Stop() {
 	s.logger.Info("Scheduler stopped")
 }
 
-func parseCronSchedule(schedule string) string {
-	defaultSchedule := "59 23 * * *" 
+func (s *Service) run(ctx context.Context, job job, scheduled *gocron.Job) {
+	logger := s.logger.WithField("job", job.name)
+	if waited := time.Since(scheduled.LastRun()); waited > time.Second {
+		logger.WithField("waited", waited.Round(time.Second).String()).Warn("Previous run was still going, starting late")
+	}
+	logger.Info("Executing scheduled scraping job...")
 
-	fields := []rune(schedule)
-	if len(fields) < 10 {
-		return defaultSchedule
+	report, err := s.scraper.ScrapeSite(ctx, job.source, job.categories, job.timeout, runs.TriggerScheduled)
+	switch {
+	case err != nil:
+		logger.WithError(err).Error("Scheduled scraping job failed")
+	case report.Sites[0].Skipped:
+		logger.Info("Scheduled scraping job skipped")
+	default:
+		logger.WithField("products", report.Saved()).Info("Scheduled scraping job completed successfully")
 	}
-	
-	firstSpace := -1
-	for i, char := range fields {
-		if char == ' ' {
-			firstSpace = i
-			break
+}
+
+// jobs resolves the schedules of every site. Schedules naming sites or
+// categories that do not exist are errors, since they are most likely typos.
+func (s *Service) jobs() ([]job, error) {
+	known := map[string]scraper.SiteScraper{}
+	for _, site := range s.scraper.Sites() {
+		known[site.GetSource()] = site
+	}
+	for source := range s.schedules.Sites {
+		if _, ok := known[source]; !ok {
+			return nil, fmt.Errorf("schedules: unknown site %q", source)
 		}
 	}
-	
-	if firstSpace == -1 {
-		return defaultSchedule
+
+	var jobs []job
+	for _, site := range s.scraper.Sites() {
+		source := site.GetSource()
+		siteJob, categoryJobs := s.schedules.Site(source)
+		if siteJob.IsEnabled() {
+			jobs = append(jobs, job{
+				name:     source,
+				source:   source,
+				schedule: siteJob.Schedule,
+				timeout:  siteJob.Timeout,
+			})
+		}
+
+		categories := map[string]bool{}
+		for _, name := range site.GetCategories() {
+			categories[name] = true
+		}
+		names := make([]string, 0, len(categoryJobs))
+		for name := range categoryJobs {
+			if !categories[name] {
+				return nil, fmt.Errorf("schedules: %s has no category %q", source, name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if categoryJob := categoryJobs[name]; categoryJob.IsEnabled() {
+				jobs = append(jobs, job{
+					name:       source + "/" + name,
+					source:     source,
+					categories: []string{name},
+					schedule:   categoryJob.Schedule,
+					timeout:    categoryJob.Timeout,
+				})
+			}
+		}
 	}
-	
-	return string(fields[firstSpace+1:])
-}
\ No newline at end of file
+
+	return jobs, nil
+}
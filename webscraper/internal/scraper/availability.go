@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"fmt"
+	"time"
+)
+
+// reconcileAvailability flags products of a source that were not seen since
+// the given time. A product is only marked unavailable after it has been missed
+// by the configured number of consecutive complete runs.
+func (s *Service) reconcileAvailability(source string, since time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE products SET missed_scrapes = missed_scrapes + 1
+		WHERE source = $1 AND is_available = true AND scraped_at < $2`,
+		source, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count missed scrapes: %w", err)
+	}
+
+	rows, err := tx.Query(`
+		UPDATE products SET is_available = false
+		WHERE source = $1 AND is_available = true AND missed_scrapes >= $2
+		RETURNING id, price`,
+		source, s.config.UnavailableAfterMisses)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark products unavailable: %w", err)
+	}
+
+	type unavailableProduct struct {
+		id    string
+		price float64
+	}
+
+	var unavailable []unavailableProduct
+	for rows.Next() {
+		var product unavailableProduct
+		if err := rows.Scan(&product.id, &product.price); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan unavailable product: %w", err)
+		}
+		unavailable = append(unavailable, product)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read unavailable products: %w", err)
+	}
+
+	now := time.Now()
+	for _, product := range unavailable {
+		if _, err := tx.Exec(priceHistoryQuery, product.id, product.price, false, now); err != nil {
+			return 0, fmt.Errorf("failed to record price history for %s: %w", product.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit availability changes: %w", err)
+	}
+
+	return len(unavailable), nil
+}
@@ -0,0 +1,369 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/pricing"
+	"shinewardrobe-scraper/internal/vtex"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StrategyCatalogAPI marks fields read from a store catalog API.
+const StrategyCatalogAPI Strategy = "catalog-api"
+
+const defaultCatalogPageSize = vtex.MaxPageSize
+
+var (
+	defaultSizeVariations  = []string{"Tamanho", "Size", "Tam"}
+	defaultColorVariations = []string{"Cor", "Color", "Cores"}
+	defaultMaterialFields  = []string{"Composição", "Composicao", "Material", "Tecido"}
+)
+
+// VTEXScraper reads a store through the VTEX catalog API. Real sizes, stock
+// and colors come from the SKUs, so no browser is needed. Brand and category
+// rules are shared with the listing scraper of the same definition.
+type VTEXScraper struct {
+	definition *SiteDefinition
+	client     *vtex.Client
+	listing    *DefinitionScraper
+	config     *config.Config
+	logger     *logrus.Entry
+}
+
+func NewVTEXScraper(definition *SiteDefinition, client *vtex.Client, cfg *config.Config, logger *logrus.Entry) *VTEXScraper {
+	return &VTEXScraper{
+		definition: definition,
+		client:     client,
+		listing:    NewDefinitionScraper(definition, nil, cfg, logger),
+		config:     cfg,
+		logger:     logger.WithFields(logrus.Fields{"scraper": definition.Source, "catalog": definition.Catalog.Type}),
+	}
+}
+
+func (v *VTEXScraper) GetName() string {
+	return v.definition.Name
+}
+
+func (v *VTEXScraper) GetSource() string {
+	return v.definition.Source
+}
+
+func (v *VTEXScraper) GetCategories() []string {
+	return v.definition.CategoryNames()
+}
+
+func (v *VTEXScraper) ScrapeProducts(ctx context.Context) ([]Product, error) {
+	products, _, err := v.ScrapeCategories(ctx, nil)
+	return products, err
+}
+
+func (v *VTEXScraper) ScrapeCategories(ctx context.Context, names []string) ([]Product, ListingStats, error) {
+	categories, err := v.definition.CategoriesNamed(names)
+	if err != nil {
+		return nil, ListingStats{}, err
+	}
+
+	v.logger.Infof("Starting %s catalog scraping...", v.definition.Name)
+
+	var tree []vtex.Category
+	if v.definition.Catalog.TreeDepth > 0 {
+		if tree, err = v.client.CategoryTree(ctx, v.definition.Catalog.TreeDepth); err != nil {
+			v.logger.WithError(err).Warn("Failed to load category tree, searching categories by path")
+		}
+	}
+
+	var products []Product
+	var stats ListingStats
+	failed := map[string]error{}
+
+	for i, category := range categories {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				failed[category.Name] = ctx.Err()
+				continue
+			case <-time.After(v.definition.Catalog.Delay):
+			}
+		}
+
+		v.logger.WithField("category", category.Name).Info("Scraping category...")
+
+		// A category failing after its first page still returns the products
+		// found so far, which are kept.
+		categoryProducts, categoryStats, err := v.scrapeCategory(ctx, category, v.categoryQuery(tree, category))
+		products = append(products, categoryProducts...)
+		stats.add(categoryStats)
+		if err != nil {
+			v.logger.WithError(err).WithFields(logrus.Fields{
+				"category": category.Name,
+				"products": len(categoryProducts),
+			}).Error("Failed to scrape category")
+			failed[category.Name] = err
+		}
+	}
+
+	if len(failed) == len(categories) && len(products) == 0 {
+		return nil, stats, fmt.Errorf("all categories failed: %v", &PartialScrapeError{Site: v.GetName(), Failed: failed})
+	}
+
+	v.logger.WithField("total_products", len(products)).Infof("%s catalog scraping completed", v.definition.Name)
+	if len(failed) > 0 {
+		return products, stats, &PartialScrapeError{Site: v.GetName(), Failed: failed}
+	}
+	return products, stats, nil
+}
+
+// categoryQuery prefers the category filter from the tree, which also covers
+// subcategories, and falls back to a search by path.
+func (v *VTEXScraper) categoryQuery(tree []vtex.Category, category CategoryDefinition) vtex.SearchQuery {
+	path := category.CatalogPath
+	if path == "" {
+		if parsed, err := url.Parse(category.URL); err == nil {
+			path = parsed.Path
+		}
+	}
+
+	if _, filter, ok := vtex.FindCategory(tree, path); ok {
+		return vtex.SearchQuery{Filter: filter}
+	}
+	return vtex.SearchQuery{Path: path}
+}
+
+// scrapeCategory returns the products of a category, counting the catalog
+// products it discarded and whether the category was capped.
+func (v *VTEXScraper) scrapeCategory(ctx context.Context, category CategoryDefinition, query vtex.SearchQuery) ([]Product, ListingStats, error) {
+	pageSize := v.definition.Catalog.PageSize
+	if pageSize == 0 {
+		pageSize = defaultCatalogPageSize
+	}
+
+	var products []Product
+	var stats ListingStats
+	capped := false
+	seen := map[string]bool{}
+
+	for from := 0; len(products) < v.config.MaxProducts; from += pageSize {
+		// Failing after the first page returns the products found so far
+		// with an error, so the products of the remaining pages are not
+		// taken for missing.
+		if from > 0 {
+			select {
+			case <-ctx.Done():
+				return products, stats, fmt.Errorf("stopped before catalog offset %d: %w", from, ctx.Err())
+			case <-time.After(v.definition.Catalog.Delay):
+			}
+		}
+
+		query.From, query.To = from, from+pageSize-1
+		page, err := v.client.Search(ctx, query)
+		if err != nil {
+			if from == 0 {
+				return nil, ListingStats{}, err
+			}
+			return products, stats, fmt.Errorf("failed to load catalog offset %d: %w", from, err)
+		}
+
+		for _, catalogProduct := range page.Products {
+			if len(products) >= v.config.MaxProducts {
+				capped = true
+				break
+			}
+			product, ok := v.toProduct(catalogProduct, category)
+			if !ok || seen[product.ProductURL] {
+				stats.Discarded++
+				continue
+			}
+			seen[product.ProductURL] = true
+			products = append(products, product)
+		}
+
+		if len(page.Products) < pageSize || (page.Total >= 0 && from+pageSize >= page.Total) {
+			break
+		}
+		// The catalog has more pages than the products wanted.
+		if len(products) >= v.config.MaxProducts {
+			capped = true
+		}
+	}
+
+	if capped {
+		stats.Capped = []string{category.Name}
+	}
+	return products, stats, nil
+}
+
+// toProduct maps a catalog product to a Product. The price is the lowest one
+// among available SKUs, sizes and colors are the ones with stock, and stock is
+// summed per size across colors.
+func (v *VTEXScraper) toProduct(catalogProduct vtex.Product, category CategoryDefinition) (Product, bool) {
+	catalog := v.definition.Catalog
+	sizeNames := firstNonEmptyList(catalog.SizeVariations, defaultSizeVariations)
+	colorNames := firstNonEmptyList(catalog.ColorVariations, defaultColorVariations)
+
+	product := Product{
+		Name:       strings.TrimSpace(catalogProduct.ProductName),
+		Brand:      strings.TrimSpace(catalogProduct.Brand),
+		ProductURL: resolveURL(v.definition.BaseURL, catalogProduct.Link),
+		Source:     v.definition.Source,
+	}
+	if product.ProductURL == "" && catalogProduct.LinkText != "" {
+		product.ProductURL = v.definition.BaseURL + "/" + catalogProduct.LinkText + "/p"
+	}
+
+	stockBySize := map[string]*SizeStock{}
+	seenColors := map[string]bool{}
+	var fallbackPrice, fallbackListPrice float64
+
+	for _, item := range catalogProduct.Items {
+		seller, ok := item.DefaultSeller()
+		if !ok {
+			continue
+		}
+		offer := seller.CommertialOffer
+		if fallbackPrice == 0 || (offer.Price > 0 && offer.Price < fallbackPrice) {
+			fallbackPrice, fallbackListPrice = offer.Price, offer.ListPrice
+		}
+
+		available := offer.Available()
+		product.Available = product.Available || available
+		if available && (product.Price == 0 || offer.Price < product.Price) {
+			product.Price = offer.Price
+			product.Seller = strings.TrimSpace(seller.SellerName)
+			product.OriginalPrice, product.Installments, product.CashPrice = nil, nil, nil
+			if offer.ListPrice > offer.Price {
+				listPrice := offer.ListPrice
+				product.OriginalPrice = &listPrice
+			}
+			if installment, ok := offer.LongestInstallments(); ok {
+				product.Installments = &pricing.Installments{
+					Count:        installment.NumberOfInstallments,
+					Value:        installment.Value,
+					InterestFree: installment.InterestRate == 0,
+				}
+			}
+			if cashPrice := offer.CashPrice(); cashPrice > 0 {
+				product.CashPrice = &cashPrice
+			}
+		}
+
+		if size := item.Variation(sizeNames...); size != "" {
+			stock, exists := stockBySize[size]
+			if !exists {
+				stock = &SizeStock{Size: size}
+				stockBySize[size] = stock
+				product.Stock = append(product.Stock, SizeStock{Size: size})
+			}
+			stock.Available = stock.Available || available
+			if available && offer.AvailableQuantity > 0 {
+				quantity := offer.AvailableQuantity
+				if stock.Quantity != nil {
+					quantity += *stock.Quantity
+				}
+				stock.Quantity = &quantity
+			}
+		}
+
+		if color := item.Variation(colorNames...); color != "" && available && !seenColors[color] {
+			seenColors[color] = true
+			product.Colors = append(product.Colors, color)
+		}
+
+		if product.ImageURL == "" {
+			for _, image := range item.Images {
+				if image.ImageURL != "" {
+					product.Images = append(product.Images, image.ImageURL)
+				}
+			}
+			if len(product.Images) > 0 {
+				product.ImageURL = product.Images[0]
+			}
+		}
+	}
+
+	// Sold-out products keep their last price and are saved as unavailable.
+	if !product.Available {
+		product.Price = fallbackPrice
+		if fallbackListPrice > fallbackPrice {
+			product.OriginalPrice = &fallbackListPrice
+		}
+	}
+	if product.Name == "" || product.Price <= 0 || product.ProductURL == "" {
+		return Product{}, false
+	}
+
+	if len(product.Stock) > 0 {
+		product.Sizes = []string{}
+	}
+	for i, stock := range product.Stock {
+		product.Stock[i] = *stockBySize[stock.Size]
+		if product.Stock[i].Available {
+			product.Sizes = append(product.Sizes, stock.Size)
+		}
+	}
+
+	product.Description = strings.Join(strings.Fields(stripTags(catalogProduct.Description)), " ")
+	product.Material = catalogProduct.Specification(firstNonEmptyList(catalog.Material, defaultMaterialFields)...)
+	if product.Material == "" {
+		if match := compositionPattern.FindStringSubmatch(product.Description); match != nil {
+			product.Material = strings.TrimSpace(match[2])
+		}
+	}
+
+	for _, field := range []struct {
+		name  string
+		found bool
+	}{
+		{FieldName, true},
+		{FieldBrand, product.Brand != ""},
+		{FieldSeller, product.Seller != ""},
+		{FieldPrice, true},
+		{FieldOriginalPrice, product.OriginalPrice != nil},
+		{FieldInstallments, product.Installments != nil},
+		{FieldCashPrice, product.CashPrice != nil},
+		{FieldProductURL, true},
+		{FieldImageURL, product.ImageURL != ""},
+		{FieldImages, len(product.Images) > 0},
+		{FieldSizes, len(product.Stock) > 0},
+		{FieldColors, len(product.Colors) > 0},
+		{FieldDescription, product.Description != ""},
+		{FieldMaterial, product.Material != ""},
+	} {
+		if field.found {
+			product.recordSource(field.name, StrategyCatalogAPI)
+		}
+	}
+
+	// VTEX lists the category paths of the product, deepest first.
+	breadcrumb := append(append([]string{}, catalogProduct.Categories...), category.Name)
+	v.listing.completeProduct(&product, listingContext{URL: category.URL, Breadcrumb: breadcrumb})
+
+	// The catalog carries the product page data, so the product counts as
+	// detailed and its sizes, colors and description are stored.
+	now := time.Now()
+	product.DetailedAt = &now
+
+	return product, true
+}
+
+func stripTags(value string) string {
+	var builder strings.Builder
+	inTag := false
+	for _, r := range value {
+		switch {
+		case r == '<':
+			inTag = true
+			builder.WriteRune(' ')
+		case r == '>':
+			inTag = false
+		case !inTag:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
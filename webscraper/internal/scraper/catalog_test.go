@@ -0,0 +1,251 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/pricing"
+	"shinewardrobe-scraper/internal/vtex"
+)
+
+// catalogProduct renders a search result with one SKU per size and color.
+func catalogProduct(id int, name string, soldOut map[string]bool) string {
+	items := ""
+	for _, color := range []string{"Azul", "Preto"} {
+		for _, size := range []string{"P", "M", "G"} {
+			quantity, available := 2, true
+			if soldOut[size] {
+				quantity, available = 0, false
+			}
+			if items != "" {
+				items += ","
+			}
+			items += fmt.Sprintf(`{"itemId": "%d-%s-%s", "variations": ["Tamanho", "Cor"], "Tamanho": [%q], "Cor": [%q],
+				"images": [{"imageUrl": "https://cea.vteximg.com.br/arquivos/%d-%s.jpg"}],
+				"sellers": [{"sellerId": "1", "sellerDefault": true, "commertialOffer": {"Price": 89.9, "ListPrice": 119.9, "AvailableQuantity": %d, "IsAvailable": %t,
+					"Installments": [
+						{"Value": 85.4, "InterestRate": 0, "NumberOfInstallments": 1, "PaymentSystemName": "Pix"},
+						{"Value": 29.96, "InterestRate": 0, "NumberOfInstallments": 3, "PaymentSystemName": "Visa"},
+						{"Value": 9.99, "InterestRate": 1.99, "NumberOfInstallments": 10, "PaymentSystemName": "Visa"}]}}]}`,
+				id, size, color, size, color, id, color, quantity, available)
+		}
+	}
+
+	return fmt.Sprintf(`{"productId": "%d", "productName": %q, "brand": "Ace", "linkText": "produto-%d",
+		"link": "https://www.cea.com.br/produto-%d/p",
+		"description": "<p>Tecido leve.</p><p>Composição: 100%% linho</p>",
+		"items": [%s]}`, id, name, id, id, items)
+}
+
+func TestVTEXScraperPagesThroughCategories(t *testing.T) {
+	const total = 5
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/catalog_system/pub/category/tree/3":
+			fmt.Fprintf(w, `[{"id": 1, "name": "Masculino", "url": "%s/masculino", "children": [
+				{"id": 4, "name": "Camisetas", "url": "%s/masculino/camisetas"}]}]`, "http://"+r.Host, "http://"+r.Host)
+			return
+		case "/api/catalog_system/pub/products/search":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		requests = append(requests, r.URL.RawQuery)
+		from, _ := strconv.Atoi(r.URL.Query().Get("_from"))
+		to, _ := strconv.Atoi(r.URL.Query().Get("_to"))
+		body := ""
+		for id := from; id <= to && id < total; id++ {
+			if body != "" {
+				body += ","
+			}
+			body += catalogProduct(id, fmt.Sprintf("Camisa Linho %d", id), map[string]bool{"G": true})
+		}
+		w.Header().Set("resources", fmt.Sprintf("%d-%d/%d", from, to, total))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprintf(w, "[%s]", body)
+	}))
+	defer server.Close()
+
+	definition := &SiteDefinition{
+		Name:     "C&A",
+		Source:   "ca",
+		BaseURL:  server.URL,
+		Brand:    BrandDefinition{Default: "C&A"},
+		Catalog:  &CatalogDefinition{Type: CatalogVTEX, PageSize: 2, TreeDepth: 3},
+		Defaults: DefaultsDefinition{Sizes: []string{"P", "M", "G", "GG"}, Colors: []string{"Variadas"}},
+		Categories: []CategoryDefinition{
+			{Name: "camisetas-masculino", URL: server.URL + "/masculino/camisetas"},
+		},
+	}
+
+	client := vtex.NewClient(server.URL, server.Client(), "")
+	scraper := NewVTEXScraper(definition, client, &config.Config{MaxProducts: 50}, testLogger())
+
+	products, err := scraper.ScrapeProducts(context.Background())
+	if err != nil {
+		t.Fatalf("ScrapeProducts: %v", err)
+	}
+	if len(products) != total {
+		t.Fatalf("got %d products, want %d", len(products), total)
+	}
+	wantRequests := []string{
+		"_from=0&_to=1&fq=C%3A%2F1%2F4%2F",
+		"_from=2&_to=3&fq=C%3A%2F1%2F4%2F",
+		"_from=4&_to=5&fq=C%3A%2F1%2F4%2F",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+
+	product := products[0]
+	if product.Price != 89.9 || product.OriginalPrice == nil || *product.OriginalPrice != 119.9 {
+		t.Errorf("prices = %v/%v, want 89.9/119.9", product.Price, product.OriginalPrice)
+	}
+	if product.CashPrice == nil || *product.CashPrice != 85.4 {
+		t.Errorf("CashPrice = %v, want 85.4", product.CashPrice)
+	}
+	if want := (pricing.Installments{Count: 3, Value: 29.96, InterestFree: true}); product.Installments == nil || *product.Installments != want {
+		t.Errorf("Installments = %+v, want %+v", product.Installments, want)
+	}
+	if want := []string{"P", "M"}; !reflect.DeepEqual(product.Sizes, want) {
+		t.Errorf("Sizes = %v, want %v", product.Sizes, want)
+	}
+	if want := []string{"Azul", "Preto"}; !reflect.DeepEqual(product.Colors, want) {
+		t.Errorf("Colors = %v, want %v", product.Colors, want)
+	}
+	if len(product.Stock) != 3 || product.Stock[0].Quantity == nil || *product.Stock[0].Quantity != 4 || product.Stock[2].Available {
+		t.Errorf("Stock = %+v, want P and M with 4 units each and G sold out", product.Stock)
+	}
+	if product.Brand != "Ace" || product.Material != "100% linho" || product.Description != "Tecido leve. Composição: 100% linho" {
+		t.Errorf("product = %+v", product)
+	}
+	if product.ProductURL != "https://www.cea.com.br/produto-0/p" {
+		t.Errorf("ProductURL = %q", product.ProductURL)
+	}
+	if product.FieldSources[FieldSizes] != StrategyCatalogAPI || product.DetailedAt == nil {
+		t.Errorf("expected catalog sizes and DetailedAt, got %v / %v", product.FieldSources, product.DetailedAt)
+	}
+}
+
+func TestVTEXScraperReportsCappedCategories(t *testing.T) {
+	const total = 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("_from"))
+		to, _ := strconv.Atoi(r.URL.Query().Get("_to"))
+		body := ""
+		for id := from; id <= to && id < total; id++ {
+			if body != "" {
+				body += ","
+			}
+			body += catalogProduct(id, fmt.Sprintf("Camisa Linho %d", id), nil)
+		}
+		w.Header().Set("resources", fmt.Sprintf("%d-%d/%d", from, to, total))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprintf(w, "[%s]", body)
+	}))
+	defer server.Close()
+
+	definition := &SiteDefinition{
+		Name:    "C&A",
+		Source:  "ca",
+		BaseURL: server.URL,
+		Brand:   BrandDefinition{Default: "C&A"},
+		Catalog: &CatalogDefinition{Type: CatalogVTEX, PageSize: 2},
+		Categories: []CategoryDefinition{
+			{Name: "camisetas-masculino", URL: server.URL + "/masculino/camisetas"},
+		},
+	}
+	client := vtex.NewClient(server.URL, server.Client(), "")
+
+	for _, test := range []struct {
+		maxProducts int
+		capped      bool
+	}{
+		{maxProducts: 1, capped: true},
+		{maxProducts: 2, capped: true},
+		{maxProducts: total, capped: false},
+		{maxProducts: 50, capped: false},
+	} {
+		scraper := NewVTEXScraper(definition, client, &config.Config{MaxProducts: test.maxProducts}, testLogger())
+		products, stats, err := scraper.ScrapeCategories(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ScrapeCategories with %d max products: %v", test.maxProducts, err)
+		}
+		if want := min(test.maxProducts, total); len(products) != want {
+			t.Errorf("got %d products with %d max products, want %d", len(products), test.maxProducts, want)
+		}
+		if capped := len(stats.Capped) > 0; capped != test.capped {
+			t.Errorf("got %v capped with %d max products, want capped %v", stats.Capped, test.maxProducts, test.capped)
+		}
+	}
+}
+
+func TestVTEXScraperReportsFailedNextPageAsPartial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("_from") != "0" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("resources", "0-1/5")
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprintf(w, "[%s,%s]", catalogProduct(0, "Camisa Linho 0", nil), catalogProduct(1, "Camisa Linho 1", nil))
+	}))
+	defer server.Close()
+
+	definition := &SiteDefinition{
+		Name:    "C&A",
+		Source:  "ca",
+		BaseURL: server.URL,
+		Brand:   BrandDefinition{Default: "C&A"},
+		Catalog: &CatalogDefinition{Type: CatalogVTEX, PageSize: 2},
+		Categories: []CategoryDefinition{
+			{Name: "camisetas-masculino", URL: server.URL + "/masculino/camisetas"},
+		},
+	}
+
+	client := vtex.NewClient(server.URL, server.Client(), "")
+	scraper := NewVTEXScraper(definition, client, &config.Config{MaxProducts: 50}, testLogger())
+
+	products, err := scraper.ScrapeProducts(context.Background())
+	partial, ok := err.(*PartialScrapeError)
+	if !ok {
+		t.Fatalf("expected *PartialScrapeError, got %v", err)
+	}
+	if _, failed := partial.Failed["camisetas-masculino"]; !failed {
+		t.Errorf("expected camisetas-masculino to be reported as failed, got %v", partial.Failed)
+	}
+	if len(products) != 2 {
+		t.Errorf("got %d products, want the 2 of the first page", len(products))
+	}
+}
+
+func TestVTEXScraperKeepsSoldOutProductsAsUnavailable(t *testing.T) {
+	var catalogProducts []vtex.Product
+	data := fmt.Sprintf("[%s,%s]", catalogProduct(0, "Camisa Linho 0", map[string]bool{"G": true}), catalogProduct(1, "Camisa Linho 1", map[string]bool{"P": true, "M": true, "G": true}))
+	if err := json.Unmarshal([]byte(data), &catalogProducts); err != nil {
+		t.Fatal(err)
+	}
+
+	definition := &SiteDefinition{Name: "C&A", Source: "ca", BaseURL: "https://www.cea.com.br", Catalog: &CatalogDefinition{Type: CatalogVTEX}}
+	scraper := NewVTEXScraper(definition, nil, &config.Config{MaxProducts: 50}, testLogger())
+	category := CategoryDefinition{Name: "camisetas-masculino"}
+
+	inStock, ok := scraper.toProduct(catalogProducts[0], category)
+	if !ok || !inStock.Available {
+		t.Errorf("product with stock: ok %v, Available %v, want both true", ok, inStock.Available)
+	}
+	soldOut, ok := scraper.toProduct(catalogProducts[1], category)
+	if !ok || soldOut.Available || soldOut.Price != 89.9 {
+		t.Errorf("sold-out product: ok %v, Available %v, Price %v, want it kept unavailable at 89.9", ok, soldOut.Available, soldOut.Price)
+	}
+}
@@ -0,0 +1,445 @@
+package scraper
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"shinewardrobe-scraper/internal/vtex"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed sites/*.yaml
+var embeddedSites embed.FS
+
+// SiteDefinition describes how to scrape a store. Definitions are read from
+// YAML (or JSON) files so selectors and categories can change without a
+// rebuild.
+type SiteDefinition struct {
+	Name         string               `yaml:"name"`
+	Source       string               `yaml:"source"`
+	BaseURL      string               `yaml:"base_url"`
+	ImageBaseURL string               `yaml:"image_base_url"`
+	Currency     string               `yaml:"currency"`
+	Marketplace  bool                 `yaml:"marketplace"`
+	Brand        BrandDefinition      `yaml:"brand"`
+	Page         PageDefinition       `yaml:"page"`
+	Pagination   PaginationDefinition `yaml:"pagination"`
+	Categories   []CategoryDefinition `yaml:"categories"`
+	Selectors    SelectorDefinition   `yaml:"selectors"`
+	Defaults     DefaultsDefinition   `yaml:"defaults"`
+	Detail       *DetailDefinition    `yaml:"detail"`
+	Catalog      *CatalogDefinition   `yaml:"catalog"`
+
+	file string
+}
+
+// BrandDefinition names the brand of products the store sells as its own.
+// Marketplaces sell other brands only and have no default.
+type BrandDefinition struct {
+	Default string `yaml:"default"`
+}
+
+type PageDefinition struct {
+	WaitSelector  string        `yaml:"wait_selector"`
+	RenderDelay   time.Duration `yaml:"render_delay"`
+	CategoryDelay time.Duration `yaml:"category_delay"`
+}
+
+const (
+	PaginationNone   = "none"
+	PaginationNext   = "next"
+	PaginationScroll = "scroll"
+)
+
+// PaginationDefinition controls how many listing pages are visited per
+// category. In "next" mode the link matched by NextSelector is followed, in
+// "scroll" mode the page is scrolled to trigger infinite loading. MaxPages
+// bounds the number of pages, or scrolls, per category.
+type PaginationDefinition struct {
+	Mode         string        `yaml:"mode"`
+	NextSelector string        `yaml:"next_selector"`
+	MaxPages     int           `yaml:"max_pages"`
+	Delay        time.Duration `yaml:"delay"`
+}
+
+type CategoryDefinition struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// CatalogPath is the category path in the catalog API when it differs
+	// from the path of URL.
+	CatalogPath string `yaml:"catalog_path"`
+}
+
+type SelectorDefinition struct {
+	Items           []string `yaml:"items"`
+	Name            []string `yaml:"name"`
+	Price           []string `yaml:"price"`
+	OriginalPrice   []string `yaml:"original_price"`
+	Installments    []string `yaml:"installments"`
+	CashPrice       []string `yaml:"cash_price"`
+	Seller          []string `yaml:"seller"`
+	Image           string   `yaml:"image"`
+	ImageAttributes []string `yaml:"image_attributes"`
+	Link            string   `yaml:"link"`
+}
+
+// DetailDefinition describes the product page used by the enrichment pass.
+// Empty selectors fall back to generic ones.
+type DetailDefinition struct {
+	WaitSelector       string        `yaml:"wait_selector"`
+	RenderDelay        time.Duration `yaml:"render_delay"`
+	Sizes              string        `yaml:"sizes"`
+	SizeUnavailable    string        `yaml:"size_unavailable"`
+	SizeStockAttribute string        `yaml:"size_stock_attribute"`
+	Colors             string        `yaml:"colors"`
+	Description        []string      `yaml:"description"`
+	Material           []string      `yaml:"material"`
+	Images             string        `yaml:"images"`
+	ImageAttributes    []string      `yaml:"image_attributes"`
+}
+
+const CatalogVTEX = "vtex"
+
+// CatalogDefinition lets a store be read through its catalog API instead of
+// rendering listing pages. TreeDepth is the depth of the category tree used to
+// resolve category paths; categories missing from the tree are searched by
+// path. A PageSize of 0 requests the largest page the API serves.
+type CatalogDefinition struct {
+	Type            string        `yaml:"type"`
+	PageSize        int           `yaml:"page_size"`
+	Delay           time.Duration `yaml:"delay"`
+	TreeDepth       int           `yaml:"tree_depth"`
+	SizeVariations  []string      `yaml:"size_variations"`
+	ColorVariations []string      `yaml:"color_variations"`
+	Material        []string      `yaml:"material"`
+}
+
+type DefaultsDefinition struct {
+	Sizes  []string `yaml:"sizes"`
+	Colors []string `yaml:"colors"`
+}
+
+var (
+	sourcePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// DefaultCurrency is used for sites that do not set a currency.
+const DefaultCurrency = "BRL"
+
+// LoadDefinitions reads every site definition in dir. When dir is empty the
+// definitions bundled with the binary are used.
+func LoadDefinitions(dir string) ([]*SiteDefinition, error) {
+	var fsys fs.FS = embeddedSites
+	root := "sites"
+	if dir != "" {
+		fsys = os.DirFS(dir)
+		root = "."
+	}
+
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site definitions: %w", err)
+	}
+
+	var definitions []*SiteDefinition
+	var errs []error
+	sources := map[string]string{}
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(root, entry.Name())))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+
+		definition, err := ParseDefinition(entry.Name(), data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if other, exists := sources[definition.Source]; exists {
+			errs = append(errs, fmt.Errorf("%s: source %q is already defined in %s", entry.Name(), definition.Source, other))
+			continue
+		}
+		sources[definition.Source] = entry.Name()
+
+		definitions = append(definitions, definition)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("no site definitions found")
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].file < definitions[j].file
+	})
+
+	return definitions, nil
+}
+
+// ParseDefinition decodes and validates a single definition. JSON documents
+// are accepted as well since they are valid YAML.
+func ParseDefinition(name string, data []byte) (*SiteDefinition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	definition := &SiteDefinition{file: name}
+	if err := decoder.Decode(definition); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode: %w", name, err)
+	}
+
+	if err := definition.Validate(); err != nil {
+		return nil, prefixErrors(name, err)
+	}
+
+	return definition, nil
+}
+
+func (d *SiteDefinition) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if strings.TrimSpace(d.Name) == "" {
+		invalid("name", "is required")
+	}
+	if !sourcePattern.MatchString(d.Source) {
+		invalid("source", "must be lowercase letters, digits, '-' or '_', got %q", d.Source)
+	}
+	if !isAbsoluteURL(d.BaseURL) {
+		invalid("base_url", "must be an absolute http(s) URL, got %q", d.BaseURL)
+	}
+	if d.ImageBaseURL != "" && !isAbsoluteURL(d.ImageBaseURL) {
+		invalid("image_base_url", "must be an absolute http(s) URL, got %q", d.ImageBaseURL)
+	}
+	if d.Currency != "" && !currencyPattern.MatchString(d.Currency) {
+		invalid("currency", "must be an ISO 4217 code such as BRL, got %q", d.Currency)
+	}
+	if strings.TrimSpace(d.Brand.Default) == "" && !d.Marketplace {
+		invalid("brand.default", "is required")
+	}
+	if strings.TrimSpace(d.Page.WaitSelector) == "" {
+		invalid("page.wait_selector", "is required")
+	}
+	if d.Page.RenderDelay < 0 || d.Page.CategoryDelay < 0 {
+		invalid("page", "delays must not be negative")
+	}
+
+	switch d.Pagination.Mode {
+	case "", PaginationNone:
+	case PaginationNext, PaginationScroll:
+		if d.Pagination.MaxPages < 1 {
+			invalid("pagination.max_pages", "must be at least 1, got %d", d.Pagination.MaxPages)
+		}
+		if d.Pagination.Mode == PaginationNext && strings.TrimSpace(d.Pagination.NextSelector) == "" {
+			invalid("pagination.next_selector", "is required in %q mode", PaginationNext)
+		}
+	default:
+		invalid("pagination.mode", "must be %q, %q or %q, got %q", PaginationNone, PaginationNext, PaginationScroll, d.Pagination.Mode)
+	}
+	if d.Pagination.Delay < 0 {
+		invalid("pagination.delay", "must not be negative")
+	}
+
+	if len(d.Categories) == 0 {
+		invalid("categories", "at least one category is required")
+	}
+	seen := map[string]bool{}
+	for i, category := range d.Categories {
+		field := fmt.Sprintf("categories[%d]", i)
+		if strings.TrimSpace(category.Name) == "" {
+			invalid(field+".name", "is required")
+		} else if seen[category.Name] {
+			invalid(field+".name", "duplicate category %q", category.Name)
+		}
+		seen[category.Name] = true
+		if !isAbsoluteURL(category.URL) {
+			invalid(field+".url", "must be an absolute http(s) URL, got %q", category.URL)
+		}
+	}
+
+	if len(d.Selectors.Items) == 0 {
+		invalid("selectors.items", "at least one selector is required")
+	}
+	if len(d.Selectors.Name) == 0 {
+		invalid("selectors.name", "at least one selector is required")
+	}
+	if len(d.Selectors.Price) == 0 {
+		invalid("selectors.price", "at least one selector is required")
+	}
+
+	if d.Detail != nil {
+		if strings.TrimSpace(d.Detail.WaitSelector) == "" {
+			invalid("detail.wait_selector", "is required")
+		}
+		if d.Detail.RenderDelay < 0 {
+			invalid("detail.render_delay", "must not be negative")
+		}
+	}
+
+	if d.Catalog != nil {
+		if d.Catalog.Type != CatalogVTEX {
+			invalid("catalog.type", "must be %q, got %q", CatalogVTEX, d.Catalog.Type)
+		}
+		// Leaving page_size out, or setting it to 0, uses the API maximum.
+		if d.Catalog.PageSize != 0 && (d.Catalog.PageSize < 1 || d.Catalog.PageSize > vtex.MaxPageSize) {
+			invalid("catalog.page_size", "must be between 1 and %d, or 0 for the API maximum, got %d", vtex.MaxPageSize, d.Catalog.PageSize)
+		}
+		if d.Catalog.Delay < 0 {
+			invalid("catalog.delay", "must not be negative")
+		}
+		if d.Catalog.TreeDepth < 0 {
+			invalid("catalog.tree_depth", "must not be negative")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SiteCurrency is the currency prices are assumed to be in when the page does
+// not show a currency symbol.
+func (d *SiteDefinition) SiteCurrency() string {
+	return firstNonEmpty(d.Currency, DefaultCurrency)
+}
+
+// CategoriesNamed returns the categories with the given names, in definition
+// order, or every category when names is empty.
+func (d *SiteDefinition) CategoriesNamed(names []string) ([]CategoryDefinition, error) {
+	if len(names) == 0 {
+		return d.Categories, nil
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var categories []CategoryDefinition
+	for _, category := range d.Categories {
+		if wanted[category.Name] {
+			categories = append(categories, category)
+			delete(wanted, category.Name)
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := make([]string, 0, len(wanted))
+		for name := range wanted {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s has no categories named %s", d.Name, strings.Join(unknown, ", "))
+	}
+	return categories, nil
+}
+
+// CategoryNames returns the names of the categories in definition order.
+func (d *SiteDefinition) CategoryNames() []string {
+	names := make([]string, len(d.Categories))
+	for i, category := range d.Categories {
+		names[i] = category.Name
+	}
+	return names
+}
+
+// WithBaseURL returns a copy of the definition whose pages are loaded from
+// baseURL instead of the retailer's host. Category paths are kept and appended
+// to baseURL, which makes it possible to point a site at a local fake store.
+func (d *SiteDefinition) WithBaseURL(baseURL string) (*SiteDefinition, error) {
+	if !isAbsoluteURL(baseURL) {
+		return nil, fmt.Errorf("%s: base URL override must be an absolute http(s) URL, got %q", d.Source, baseURL)
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	override := *d
+	override.BaseURL = baseURL
+	if d.ImageBaseURL != "" {
+		override.ImageBaseURL = baseURL
+	}
+
+	override.Categories = make([]CategoryDefinition, len(d.Categories))
+	for i, category := range d.Categories {
+		parsed, err := url.Parse(category.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: categories[%d].url: %w", d.Source, i, err)
+		}
+		category.URL = baseURL + parsed.RequestURI()
+		override.Categories[i] = category
+	}
+
+	return &override, nil
+}
+
+// ApplyBaseURLOverrides replaces the base URL of the definitions listed in
+// overrides, keyed by source.
+func ApplyBaseURLOverrides(definitions []*SiteDefinition, overrides map[string]string) ([]*SiteDefinition, error) {
+	result := make([]*SiteDefinition, len(definitions))
+	applied := map[string]bool{}
+
+	for i, definition := range definitions {
+		result[i] = definition
+
+		baseURL, exists := overrides[definition.Source]
+		if !exists {
+			continue
+		}
+
+		override, err := definition.WithBaseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = override
+		applied[definition.Source] = true
+	}
+
+	for source := range overrides {
+		if !applied[source] {
+			return nil, fmt.Errorf("base URL override for unknown source %q", source)
+		}
+	}
+
+	return result, nil
+}
+
+// prefixErrors prefixes every error joined in err so each line of the final
+// message names the file it came from.
+func prefixErrors(prefix string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, fmt.Errorf("%s: %w", prefix, e))
+	}
+	return errors.Join(errs...)
+}
+
+func isAbsoluteURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,255 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"shinewardrobe-scraper/internal/taxonomy"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+var compositionPattern = regexp.MustCompile(`(?i)(composição|composicao|material|tecido)\s*:\s*([^\n.;]+)`)
+
+func (d *DefinitionScraper) HasDetailPages() bool {
+	return d.definition.Detail != nil
+}
+
+// EnrichProduct visits the product page and replaces the listing defaults with
+// the sizes, colors, description, material and images found there.
+func (d *DefinitionScraper) EnrichProduct(ctx context.Context, product *Product) error {
+	detail := d.definition.Detail
+	if detail == nil {
+		return fmt.Errorf("%s has no product detail definition", d.definition.Name)
+	}
+
+	htmlContent, err := d.fetcher.FetchPage(ctx, product.ProductURL, PageDefinition{
+		WaitSelector: detail.WaitSelector,
+		RenderDelay:  detail.RenderDelay,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load product page: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	d.applyDetail(doc, product)
+	return nil
+}
+
+func (d *DefinitionScraper) applyDetail(doc *goquery.Document, product *Product) {
+	detail := d.definition.Detail
+
+	if stock := extractSizes(doc, detail.Sizes, detail.SizeUnavailable, detail.SizeStockAttribute); len(stock) > 0 {
+		product.Stock = stock
+		product.markSelector(FieldSizes, true)
+		product.Sizes = []string{}
+		for _, size := range stock {
+			if size.Available {
+				product.Sizes = append(product.Sizes, size.Size)
+			}
+		}
+	}
+
+	if colors := extractColors(doc, detail.Colors); len(colors) > 0 {
+		product.Colors = colors
+		product.markSelector(FieldColors, true)
+	}
+
+	root := doc.Selection
+	if description := trySelectors(root, detail.Description); description != "" {
+		product.Description = strings.Join(strings.Fields(description), " ")
+		product.markSelector(FieldDescription, true)
+	}
+
+	if material := trySelectors(root, detail.Material); material != "" {
+		product.Material = strings.Join(strings.Fields(material), " ")
+		product.markSelector(FieldMaterial, true)
+	} else if match := compositionPattern.FindStringSubmatch(product.Description); match != nil {
+		product.Material = strings.TrimSpace(match[2])
+		product.markSelector(FieldMaterial, true)
+	}
+
+	var images []string
+	seen := map[string]bool{}
+	doc.Find(firstNonEmpty(detail.Images, "img")).Each(func(i int, s *goquery.Selection) {
+		for _, attribute := range firstNonEmptyList(detail.ImageAttributes, []string{"src", "data-src"}) {
+			value, _ := s.Attr(attribute)
+			if value == "" || strings.HasPrefix(value, "data:") {
+				continue
+			}
+			imageURL := resolveURL(firstNonEmpty(d.definition.ImageBaseURL, d.definition.BaseURL), value)
+			if !seen[imageURL] {
+				seen[imageURL] = true
+				images = append(images, imageURL)
+			}
+			break
+		}
+	})
+	if len(images) > 0 {
+		product.Images = images
+		product.markSelector(FieldImages, true)
+		if product.ImageURL == "" {
+			product.ImageURL = images[0]
+			product.markSelector(FieldImageURL, true)
+		}
+	}
+
+	d.applyDetailStructured(doc, product)
+
+	// The description may name what the listing did not.
+	if product.Category == taxonomy.Unknown {
+		classifyProduct(product, listingContext{})
+	}
+	assessClimate(d.climate, product)
+
+	now := time.Now()
+	product.DetailedAt = &now
+}
+
+// applyDetailStructured prefers the descriptive fields of structured data on
+// the product page over the ones found through selectors. Prices stay the
+// ones seen on the listing. Product pages often describe related products as
+// well, so only the structured product with the page URL is used, or a lone
+// one without URL.
+func (d *DefinitionScraper) applyDetailStructured(doc *goquery.Document, product *Product) {
+	structured := extractStructuredData(doc)
+	data, ok := d.detailStructured(structured, product.ProductURL)
+	if !ok {
+		return
+	}
+
+	if data.Description != "" {
+		product.Description = strings.Join(strings.Fields(data.Description), " ")
+		product.recordSource(FieldDescription, data.strategy)
+	}
+	if data.Material != "" {
+		product.Material = data.Material
+		product.recordSource(FieldMaterial, data.strategy)
+	}
+	// Structured data names the color of the variant on display only, so
+	// it is used only when the page has no color selector.
+	if data.Color != "" && product.FieldSources[FieldColors] != StrategySelector {
+		product.Colors = []string{data.Color}
+		product.recordSource(FieldColors, data.strategy)
+	}
+	if len(data.Images) > 0 {
+		product.Images = make([]string, len(data.Images))
+		for i, image := range data.Images {
+			product.Images[i] = resolveURL(firstNonEmpty(d.definition.ImageBaseURL, d.definition.BaseURL), image)
+		}
+		product.recordSource(FieldImages, data.strategy)
+		if product.ImageURL == "" {
+			product.ImageURL = product.Images[0]
+			product.recordSource(FieldImageURL, data.strategy)
+		}
+	}
+}
+
+// detailStructured picks the structured product describing productURL.
+func (d *DefinitionScraper) detailStructured(structured []structuredProduct, productURL string) (structuredProduct, bool) {
+	key := productKey(productURL)
+	for _, candidate := range structured {
+		if candidate.URL != "" && productKey(resolveURL(d.definition.BaseURL, candidate.URL)) == key {
+			return candidate, true
+		}
+	}
+	if len(structured) == 1 && structured[0].URL == "" {
+		return structured[0], true
+	}
+	return structuredProduct{}, false
+}
+
+// enrichProducts runs the detail pass for scrapers that support it. Products
+// detailed within the refresh window are skipped, and at most the configured
+// number of product pages is visited per site run.
+func (s *Service) enrichProducts(ctx context.Context, scraper SiteScraper, products []Product) int {
+	enricher, ok := scraper.(DetailEnricher)
+	if !ok || !enricher.HasDetailPages() || !s.config.DetailEnabled || len(products) == 0 {
+		return 0
+	}
+
+	logger := s.logger.WithField("site", scraper.GetName())
+
+	// Without a refresh window every product is detailed again.
+	var fresh map[string]bool
+	if s.config.DetailRefreshAfter > 0 {
+		var err error
+		fresh, err = s.recentlyDetailed(products, time.Now().Add(-s.config.DetailRefreshAfter))
+		if err != nil {
+			logger.WithError(err).Warn("Failed to look up detailed products, enriching all")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.DetailTimeout)
+	defer cancel()
+
+	attempts, enriched := 0, 0
+	for i := range products {
+		if attempts >= s.config.DetailMaxPerRun {
+			break
+		}
+		// Children's clothing is dropped after the pass, unless kept.
+		if fresh[products[i].ProductURL] || products[i].Kids && !s.config.IncludeKids {
+			continue
+		}
+
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+				logger.WithError(ctx.Err()).Warn("Product detail pass interrupted")
+				return enriched
+			case <-time.After(s.config.DetailDelay):
+			}
+		}
+		attempts++
+
+		if err := enricher.EnrichProduct(ctx, &products[i]); err != nil {
+			logger.WithError(err).WithField("product_url", products[i].ProductURL).Warn("Failed to enrich product")
+			continue
+		}
+		enriched++
+	}
+
+	logger.WithFields(logrus.Fields{
+		"attempted": attempts,
+		"enriched":  enriched,
+	}).Info("Product detail pass completed")
+
+	return enriched
+}
+
+func (s *Service) recentlyDetailed(products []Product, since time.Time) (map[string]bool, error) {
+	urls := make([]string, len(products))
+	for i, product := range products {
+		urls[i] = product.ProductURL
+	}
+
+	rows, err := s.db.Query(`
+		SELECT product_url FROM products
+		WHERE product_url = ANY($1) AND detailed_at > $2`,
+		pq.Array(urls), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query detailed products: %w", err)
+	}
+	defer rows.Close()
+
+	fresh := map[string]bool{}
+	for rows.Next() {
+		var productURL string
+		if err := rows.Scan(&productURL); err != nil {
+			return nil, fmt.Errorf("failed to scan detailed product: %w", err)
+		}
+		fresh[productURL] = true
+	}
+
+	return fresh, rows.Err()
+}
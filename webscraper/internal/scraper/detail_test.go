@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"shinewardrobe-scraper/internal/config"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestApplyDetailReplacesListingDefaults(t *testing.T) {
+	var definition *SiteDefinition
+	for _, candidate := range loadTestDefinitions(t) {
+		if candidate.Source == "zara" {
+			definition = candidate
+		}
+	}
+	if definition == nil || definition.Detail == nil {
+		t.Fatal("expected the zara definition to describe product pages")
+	}
+
+	htmlContent, err := os.ReadFile(filepath.Join(fixturesDir, "zara", "detail.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(htmlContent)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scraper := NewDefinitionScraper(definition, nil, &config.Config{MaxProducts: 50}, testLogger())
+	product := Product{
+		Name:     "Camiseta Básica Algodão",
+		Category: "shirt",
+		Sizes:    definition.Defaults.Sizes,
+		Colors:   definition.Defaults.Colors,
+	}
+	scraper.applyDetail(doc, &product)
+
+	if want := []string{"P", "G"}; !reflect.DeepEqual(product.Sizes, want) {
+		t.Errorf("Sizes = %v, want %v", product.Sizes, want)
+	}
+	if len(product.Stock) != 4 || product.Stock[1].Available {
+		t.Errorf("Stock = %+v, want 4 sizes with M unavailable", product.Stock)
+	}
+	if want := []string{"Branco", "Preto"}; !reflect.DeepEqual(product.Colors, want) {
+		t.Errorf("Colors = %v, want %v", product.Colors, want)
+	}
+	if product.Material != "100% algodão" {
+		t.Errorf("Material = %q, want %q", product.Material, "100% algodão")
+	}
+	if !strings.HasPrefix(product.Description, "Camiseta de manga curta") {
+		t.Errorf("Description = %q", product.Description)
+	}
+	wantImages := []string{
+		"https://static.zara.net/photos/camiseta-basica-1.jpg",
+		"https://static.zara.net/photos/camiseta-basica-2.jpg",
+	}
+	if !reflect.DeepEqual(product.Images, wantImages) {
+		t.Errorf("Images = %v, want %v", product.Images, wantImages)
+	}
+	if product.ImageURL != wantImages[0] {
+		t.Errorf("ImageURL = %q, want %q", product.ImageURL, wantImages[0])
+	}
+	if product.DetailedAt == nil {
+		t.Error("expected DetailedAt to be set")
+	}
+}
+
+func TestApplyDetailStructuredIgnoresOtherProducts(t *testing.T) {
+	definition := &SiteDefinition{Name: "Zara", Source: "zara", BaseURL: "https://www.zara.com"}
+	scraper := NewDefinitionScraper(definition, nil, &config.Config{MaxProducts: 50}, testLogger())
+
+	related := `<script type="application/ld+json">{"@type": "Product", "name": "Calça Linho", "url": "/br/pt/calca-linho-p2.html",
+		"description": "Calça de linho", "material": "100% linho"}</script>`
+	own := `<script type="application/ld+json">{"@type": "Product", "name": "Camiseta Básica", "description": "Camiseta de algodão"}</script>`
+
+	tests := []struct {
+		name        string
+		scripts     string
+		description string
+	}{
+		{name: "related product only", scripts: related, description: "Da listagem"},
+		{name: "lone product without URL", scripts: own, description: "Camiseta de algodão"},
+		{name: "related product and one without URL", scripts: related + own, description: "Da listagem"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.scripts + "</head><body></body></html>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			product := Product{ProductURL: "https://www.zara.com/br/pt/camiseta-basica-p1.html", Description: "Da listagem"}
+			scraper.applyDetailStructured(doc, &product)
+			if product.Description != tt.description {
+				t.Errorf("Description = %q, want %q", product.Description, tt.description)
+			}
+			if product.Material != "" {
+				t.Errorf("Material = %q, want none", product.Material)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+//go:build e2e
+
+// End-to-end tests that drive a real Chrome against the fake store. Run with
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags e2e ./internal/scraper
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"shinewardrobe-scraper/internal/browser"
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/database"
+	"shinewardrobe-scraper/internal/runs"
+	"shinewardrobe-scraper/internal/scraper/fakestore"
+)
+
+func newTestPool(t *testing.T) *browser.Pool {
+	t.Helper()
+
+	pool := browser.NewPool(browser.Options{
+		RemoteURL:    os.Getenv("CHROME_REMOTE_URL"),
+		Headless:     true,
+		WindowWidth:  1280,
+		WindowHeight: 800,
+	}, testLogger())
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func fastDefinitions(t *testing.T) []*SiteDefinition {
+	definitions := loadTestDefinitions(t)
+	for _, definition := range definitions {
+		definition.Page.RenderDelay = 0
+	}
+	return definitions
+}
+
+func TestBrowserFetcherAgainstFakeStore(t *testing.T) {
+	store := fakestore.New()
+	defer store.Close()
+
+	definitions := serveDefinitions(t, store, fastDefinitions(t), func(definition *SiteDefinition, category CategoryDefinition, listing *fakestore.Listing) {
+		listing.RenderDelay = 500 * time.Millisecond
+		listing.LazyImages = true
+	})
+
+	fetcher := NewBrowserFetcher(newTestPool(t))
+	cfg := &config.Config{MaxProducts: 50}
+
+	for _, definition := range definitions {
+		scraper := NewDefinitionScraper(definition, fetcher, cfg, testLogger())
+		category := definition.Categories[0]
+
+		products, _, err := scraper.scrapeCategory(context.Background(), category)
+		if err != nil {
+			t.Fatalf("%s: scrapeCategory: %v", definition.Source, err)
+		}
+		if len(products) != 2 {
+			t.Fatalf("%s: got %d products, want 2", definition.Source, len(products))
+		}
+		if !strings.HasSuffix(products[0].ImageURL, "/img/camiseta.jpg") {
+			t.Errorf("%s: lazy image not resolved, got %q", definition.Source, products[0].ImageURL)
+		}
+	}
+}
+
+func TestBrowserFetcherScrollsInfiniteListings(t *testing.T) {
+	store := fakestore.New()
+	defer store.Close()
+
+	var products []fakestore.Product
+	for page := 1; page <= 3; page++ {
+		products = append(products, pagedProducts(page)...)
+	}
+	store.AddListing("/infinite", fakestore.Listing{Markup: fakestore.MarkupZara, Products: products, ScrollBatch: 2})
+
+	fetcher := NewBrowserFetcher(newTestPool(t))
+	definition := &SiteDefinition{
+		Source:     "zara",
+		BaseURL:    store.URL(),
+		Page:       PageDefinition{WaitSelector: ".product-item"},
+		Pagination: PaginationDefinition{Mode: PaginationScroll, MaxPages: 5, Delay: 500 * time.Millisecond},
+		Selectors:  SelectorDefinition{Items: []string{".product-item"}, Name: []string{".product-name"}, Price: []string{".price"}},
+	}
+	scraper := NewDefinitionScraper(definition, fetcher, &config.Config{MaxProducts: 50}, testLogger())
+
+	found, _, err := scraper.scrapeCategory(context.Background(), CategoryDefinition{Name: "camisetas", URL: store.URL() + "/infinite"})
+	if err != nil {
+		t.Fatalf("scrapeCategory: %v", err)
+	}
+	if len(found) != len(products) {
+		t.Errorf("got %d products after scrolling, want %d", len(found), len(products))
+	}
+}
+
+func TestBrowserFetcherReportsErrorPages(t *testing.T) {
+	store := fakestore.New()
+	defer store.Close()
+
+	store.AddListing("/broken", fakestore.Listing{Markup: fakestore.MarkupZara, Status: http.StatusInternalServerError})
+
+	fetcher := NewBrowserFetcher(newTestPool(t))
+	_, err := fetcher.FetchPage(context.Background(), store.URL()+"/broken", PageDefinition{WaitSelector: ".product-item"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 500") {
+		t.Fatalf("expected HTTP 500 error, got %v", err)
+	}
+}
+
+func TestScrapeAllAgainstFakeStore(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewConnection(databaseURL)
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	defer db.Close()
+
+	store := fakestore.New()
+	defer store.Close()
+	t.Cleanup(func() { deleteStoreProducts(t, db, store.URL()) })
+
+	definitions := serveDefinitions(t, store, fastDefinitions(t), nil)
+	cfg := &config.Config{MaxProducts: 50, Concurrency: 2, UnavailableAfterMisses: 1}
+
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+
+	service := NewService(db, newTestPool(t), cfg, definitions, nil, tiers, testLogger())
+	report, err := service.ScrapeAll(context.Background(), runs.TriggerManual)
+	if err != nil {
+		t.Fatalf("ScrapeAll: %v", err)
+	}
+	if len(report.Sites) != len(definitions) {
+		t.Errorf("got %d sites in the report, want %d", len(report.Sites), len(definitions))
+	}
+
+	want := 0
+	for _, definition := range definitions {
+		want += 2 * len(definition.Categories)
+	}
+
+	var saved, history int
+	err = db.QueryRow(`SELECT COUNT(*) FROM products WHERE product_url LIKE $1 AND is_available`, store.URL()+"/%").Scan(&saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM product_price_history h
+		JOIN products p ON p.id = h.product_id
+		WHERE p.product_url LIKE $1`, store.URL()+"/%").Scan(&history)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved != want {
+		t.Errorf("got %d saved products, want %d", saved, want)
+	}
+	if history != want {
+		t.Errorf("got %d price history rows, want %d", history, want)
+	}
+
+	latest, err := runs.NewRepository(db).Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	recorded := map[string]runs.Run{}
+	for _, run := range latest {
+		recorded[run.Source] = run
+	}
+	for _, definition := range definitions {
+		run, ok := recorded[definition.Source]
+		if !ok {
+			t.Errorf("no run recorded for %s", definition.Source)
+			continue
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM scrape_runs WHERE run_id = $1`, run.RunID) })
+
+		if run.Trigger != runs.TriggerManual || run.Status != runs.StatusSucceeded || run.FinishedAt == nil {
+			t.Errorf("%s: got trigger %q, status %q, finished %v", definition.Source, run.Trigger, run.Status, run.FinishedAt)
+		}
+		if found := 2 * len(definition.Categories); run.Found != found || run.Inserted+run.Updated+run.Rejected != found {
+			t.Errorf("%s: got %d found, %d inserted, %d updated, %d rejected, want %d found", definition.Source, run.Found, run.Inserted, run.Updated, run.Rejected, found)
+		}
+	}
+}
+
+func TestCappedRunKeepsUnseenProductsAvailable(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewConnection(databaseURL)
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	defer db.Close()
+
+	store := fakestore.New()
+	defer store.Close()
+	t.Cleanup(func() { deleteStoreProducts(t, db, store.URL()) })
+
+	definitions := serveDefinitions(t, store, fastDefinitions(t), nil)
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+	pool := newTestPool(t)
+
+	// The first run lists every product, the second only the first of each
+	// category, so the others would be missed if the run was reconciled.
+	for _, maxProducts := range []int{50, 1} {
+		cfg := &config.Config{MaxProducts: maxProducts, Concurrency: 2, UnavailableAfterMisses: 1}
+		report, err := NewService(db, pool, cfg, definitions, nil, tiers, testLogger()).ScrapeAll(context.Background(), runs.TriggerManual)
+		if err != nil {
+			t.Fatalf("ScrapeAll with %d max products: %v", maxProducts, err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM scrape_runs WHERE run_id = $1`, report.RunID) })
+
+		for _, site := range report.Sites {
+			if capped := len(site.Capped) > 0; capped != (maxProducts == 1) {
+				t.Errorf("%s with %d max products: got %v capped", site.Source, maxProducts, site.Capped)
+			}
+			if site.Unavailable != 0 {
+				t.Errorf("%s with %d max products: marked %d products unavailable", site.Source, maxProducts, site.Unavailable)
+			}
+		}
+	}
+
+	var missed int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM products
+		WHERE product_url LIKE $1 AND (missed_scrapes > 0 OR NOT is_available)`, store.URL()+"/%").Scan(&missed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missed != 0 {
+		t.Errorf("got %d products missed by the capped run, want none", missed)
+	}
+}
+
+func deleteStoreProducts(t *testing.T, db *sql.DB, storeURL string) {
+	_, err := db.Exec(`
+		DELETE FROM product_price_history WHERE product_id IN (
+			SELECT id FROM products WHERE product_url LIKE $1
+		)`, storeURL+"/%")
+	if err != nil {
+		t.Errorf("failed to clean up price history: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM products WHERE product_url LIKE $1`, storeURL+"/%"); err != nil {
+		t.Errorf("failed to clean up products: %v", err)
+	}
+}
+
+func TestSiteLockSkipsConcurrentRuns(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewConnection(databaseURL)
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	defer db.Close()
+
+	service := &Service{db: db, config: &config.Config{LockWait: 50 * time.Millisecond}, logger: testLogger()}
+	source := "lock-test-" + time.Now().Format("150405.000")
+
+	lock, err := service.lockSite(context.Background(), source, testLogger())
+	if err != nil || lock == nil {
+		t.Fatalf("lockSite = %v, %v, want the lock", lock, err)
+	}
+
+	second, err := service.lockSite(context.Background(), source, testLogger())
+	if err != nil || second != nil {
+		t.Fatalf("second lockSite = %v, %v, want the run skipped", second, err)
+	}
+
+	if err := lock.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	third, err := service.lockSite(context.Background(), source, testLogger())
+	if err != nil || third == nil {
+		t.Fatalf("lockSite after release = %v, %v, want the lock", third, err)
+	}
+	third.release()
+}
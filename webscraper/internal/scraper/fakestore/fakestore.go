@@ -0,0 +1,230 @@
+// Package fakestore serves listing pages that mimic the markup of the
+// supported retailers so the browser based scraping path can be exercised
+// without network access.
+package fakestore
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Markup string
+
+const (
+	MarkupZara       Markup = "zara"
+	MarkupRenner     Markup = "renner"
+	MarkupCA         Markup = "ca"
+	MarkupAmericanas Markup = "americanas"
+)
+
+type Product struct {
+	Name          string
+	Price         string
+	OriginalPrice string
+	Image         string
+	Path          string
+}
+
+type Listing struct {
+	Markup   Markup
+	Products []Product
+	// RenderDelay keeps the product grid out of the DOM until the delay has
+	// passed, like client side rendered storefronts.
+	RenderDelay time.Duration
+	// LazyImages only sets data-src on images and swaps it into src after
+	// the grid is rendered.
+	LazyImages bool
+	// Status other than 200 serves an error page instead of the listing.
+	Status int
+	// Next is the path of the following page, rendered as a rel="next" link.
+	Next string
+	// ScrollBatch renders only that many products up front and appends the
+	// next batch each time the page is scrolled to the bottom.
+	ScrollBatch int
+}
+
+type Store struct {
+	server *httptest.Server
+
+	mu       sync.RWMutex
+	listings map[string]Listing
+	hits     map[string]int
+}
+
+func New() *Store {
+	store := &Store{
+		listings: map[string]Listing{},
+		hits:     map[string]int{},
+	}
+	store.server = httptest.NewServer(http.HandlerFunc(store.serveHTTP))
+	return store
+}
+
+func (s *Store) URL() string {
+	return s.server.URL
+}
+
+func (s *Store) Close() {
+	s.server.Close()
+}
+
+// AddListing registers a listing page at path, e.g. "/zara/br/pt/homem".
+func (s *Store) AddListing(path string, listing Listing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listings[path] = listing
+}
+
+func (s *Store) Hits(path string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hits[path]
+}
+
+func (s *Store) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+	listing, exists := s.listings[r.URL.Path]
+	s.mu.Unlock()
+
+	if !exists {
+		if strings.HasSuffix(r.URL.Path, ".jpg") {
+			w.Header().Set("Content-Type", "image/jpeg")
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	if listing.Status != 0 && listing.Status != http.StatusOK {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(listing.Status)
+		fmt.Fprintf(w, "<html><body><h1>Erro %d</h1><p>Tente novamente mais tarde.</p></body></html>", listing.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, newPage(listing)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type page struct {
+	Listing
+	Items   template.HTML
+	Batches []template.HTML
+	DelayMS int64
+}
+
+func newPage(listing Listing) page {
+	batchSize := listing.ScrollBatch
+	if batchSize <= 0 || batchSize > len(listing.Products) {
+		batchSize = len(listing.Products)
+	}
+
+	batches := []template.HTML{renderItems(listing, 0, batchSize)}
+	for start := batchSize; start < len(listing.Products); start += batchSize {
+		end := start + batchSize
+		if end > len(listing.Products) {
+			end = len(listing.Products)
+		}
+		batches = append(batches, renderItems(listing, start, end))
+	}
+
+	return page{
+		Listing: listing,
+		Items:   batches[0],
+		Batches: batches[1:],
+		DelayMS: listing.RenderDelay.Milliseconds(),
+	}
+}
+
+func renderItems(listing Listing, start, end int) template.HTML {
+	listing.Products = listing.Products[start:end]
+
+	var items strings.Builder
+	if err := itemTemplates.ExecuteTemplate(&items, string(listing.Markup), listing); err != nil {
+		items.WriteString(template.HTMLEscapeString(err.Error()))
+	}
+	return template.HTML(items.String())
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head><meta charset="utf-8"><title>Fake store</title></head>
+<body>
+{{if or .RenderDelay .LazyImages}}<div id="root"></div>
+<template id="items">{{.Items}}</template>
+<script>
+setTimeout(function () {
+  var items = document.getElementById("items");
+  document.getElementById("root").innerHTML = items.innerHTML;
+  items.remove();
+  document.querySelectorAll("img[data-src]").forEach(function (img) {
+    img.setAttribute("src", img.getAttribute("data-src"));
+  });
+}, {{.DelayMS}});
+</script>
+{{else}}<div id="root">{{.Items}}</div>{{end}}
+{{range .Batches}}<template class="batch">{{.}}</template>
+{{end}}{{if .Batches}}<div id="spacer" style="height: 2000px"></div>
+<script>
+window.addEventListener("scroll", function () {
+  if (window.innerHeight + window.scrollY < document.body.offsetHeight - 10) {
+    return;
+  }
+  var batch = document.querySelector("template.batch");
+  if (!batch) {
+    return;
+  }
+  document.getElementById("root").insertAdjacentHTML("beforeend", batch.innerHTML);
+  batch.remove();
+});
+</script>
+{{end}}{{if .Next}}<nav class="pagination"><a rel="next" href="{{.Next}}">Próxima</a></nav>{{end}}
+</body>
+</html>
+`))
+
+var itemTemplates = template.Must(template.New("items").Parse(`
+{{define "zara"}}<ul class="product-grid">
+{{range .Products}}<li class="product-item">
+  <a href="{{.Path}}"><img {{if $.LazyImages}}data-src{{else}}src{{end}}="{{.Image}}" alt=""></a>
+  <h3 class="product-name">{{.Name}}</h3>
+  {{if .OriginalPrice}}<span class="price-old">{{.OriginalPrice}}</span>{{end}}
+  <span class="price">{{.Price}}</span>
+</li>
+{{end}}</ul>{{end}}
+
+{{define "renner"}}<div class="showcase">
+{{range .Products}}<div class="showcase-item">
+  <a href="{{.Path}}"><img data-src="{{.Image}}" alt=""></a>
+  <p class="showcase-item-name">{{.Name}}</p>
+  {{if .OriginalPrice}}<span class="preco-original">{{.OriginalPrice}}</span>{{end}}
+  <span class="preco-promocional">{{.Price}}</span>
+</div>
+{{end}}</div>{{end}}
+
+{{define "ca"}}<section class="product-list">
+{{range .Products}}<article class="product-tile">
+  <a href="{{.Path}}"><img {{if $.LazyImages}}data-src{{else}}src{{end}}="{{.Image}}" alt=""></a>
+  <h3 class="product-title">{{.Name}}</h3>
+  {{if .OriginalPrice}}<s class="price-original">{{.OriginalPrice}}</s>{{end}}
+  <strong class="price-current">{{.Price}}</strong>
+</article>
+{{end}}</section>{{end}}
+
+{{define "americanas"}}<div class="grid">
+{{range .Products}}<div class="product-grid-item">
+  <a href="{{.Path}}"><img data-original="{{.Image}}" alt=""></a>
+  <h2 class="product-name">{{.Name}}</h2>
+  {{if .OriginalPrice}}<span class="list-price">{{.OriginalPrice}}</span>{{end}}
+  <span class="sales-price">{{.Price}}</span>
+</div>
+{{end}}</div>{{end}}
+`))
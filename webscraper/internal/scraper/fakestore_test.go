@@ -0,0 +1,244 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/scraper/fakestore"
+)
+
+// serveDefinitions points every definition at store and registers a listing
+// for each of its categories. It returns the overridden definitions.
+func serveDefinitions(t *testing.T, store *fakestore.Store, definitions []*SiteDefinition, configure func(*SiteDefinition, CategoryDefinition, *fakestore.Listing)) []*SiteDefinition {
+	t.Helper()
+
+	overrides := map[string]string{}
+	for _, definition := range definitions {
+		overrides[definition.Source] = store.URL() + "/" + definition.Source
+	}
+
+	served, err := ApplyBaseURLOverrides(definitions, overrides)
+	if err != nil {
+		t.Fatalf("ApplyBaseURLOverrides: %v", err)
+	}
+
+	for _, definition := range served {
+		for i, category := range definition.Categories {
+			listing := fakestore.Listing{
+				Markup: fakestore.Markup(definition.Source),
+				Products: []fakestore.Product{
+					{Name: fmt.Sprintf("Camiseta Teste %d", i), Price: "R$ 59,90", Image: "/img/camiseta.jpg", Path: fmt.Sprintf("/p/camiseta-%s-%d", category.Name, i)},
+					{Name: fmt.Sprintf("Calça Teste %d", i), Price: "R$ 149,90", OriginalPrice: "R$ 199,90", Image: "/img/calca.jpg", Path: fmt.Sprintf("/p/calca-%s-%d", category.Name, i)},
+				},
+			}
+			if configure != nil {
+				configure(definition, category, &listing)
+			}
+
+			parsed, err := url.Parse(category.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store.AddListing(parsed.Path, listing)
+		}
+	}
+
+	return served
+}
+
+// HTTPFetcher loads pages with a plain GET request. It does not run scripts,
+// which the fake store does not need.
+type HTTPFetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+func NewHTTPFetcher(client *http.Client, userAgent string) *HTTPFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPFetcher{client: client, userAgent: userAgent}
+}
+
+func (f *HTTPFetcher) FetchPage(ctx context.Context, pageURL string, page PageDefinition) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if f.userAgent != "" {
+		request.Header.Set("User-Agent", f.userAgent)
+	}
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to load page: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		return "", fmt.Errorf("failed to load page: HTTP %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read page: %w", err)
+	}
+
+	return string(body), nil
+}
+
+func (f *HTTPFetcher) FetchScrolled(ctx context.Context, pageURL string, page PageDefinition, scroll ScrollOptions) (string, error) {
+	return f.FetchPage(ctx, pageURL, page)
+}
+
+func TestFakeStoreMarkupMatchesDefinitions(t *testing.T) {
+	store := fakestore.New()
+	defer store.Close()
+
+	definitions := serveDefinitions(t, store, loadTestDefinitions(t), nil)
+	cfg := &config.Config{MaxProducts: 50}
+
+	for _, definition := range definitions {
+		scraper := NewDefinitionScraper(definition, nil, cfg, testLogger())
+
+		for _, category := range definition.Categories {
+			response, err := http.Get(category.URL)
+			if err != nil {
+				t.Fatalf("GET %s: %v", category.URL, err)
+			}
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+
+			products, _, _, err := scraper.parsePage(string(body), category.URL, category.Name)
+			if err != nil {
+				t.Fatalf("%s/%s: parsePage: %v", definition.Source, category.Name, err)
+			}
+			if len(products) != 2 {
+				t.Fatalf("%s/%s: got %d products, want 2", definition.Source, category.Name, len(products))
+			}
+			for _, product := range products {
+				if !strings.HasPrefix(product.ProductURL, definition.BaseURL+"/p/") {
+					t.Errorf("%s/%s: product URL %q not served by the fake store", definition.Source, category.Name, product.ProductURL)
+				}
+			}
+			if products[1].OriginalPrice == nil || *products[1].OriginalPrice != 199.90 {
+				t.Errorf("%s/%s: original price not extracted: %v", definition.Source, category.Name, products[1].OriginalPrice)
+			}
+		}
+	}
+}
+
+func TestFakeStoreErrorPage(t *testing.T) {
+	store := fakestore.New()
+	defer store.Close()
+
+	store.AddListing("/broken", fakestore.Listing{Markup: fakestore.MarkupZara, Status: http.StatusServiceUnavailable})
+
+	response, err := http.Get(store.URL() + "/broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", response.StatusCode, http.StatusServiceUnavailable)
+	}
+	if store.Hits("/broken") != 1 {
+		t.Errorf("got %d hits, want 1", store.Hits("/broken"))
+	}
+}
+
+func TestWithBaseURLKeepsCategoryPaths(t *testing.T) {
+	definition := loadTestDefinitions(t)[0]
+
+	override, err := definition.WithBaseURL("http://127.0.0.1:8080/store/")
+	if err != nil {
+		t.Fatalf("WithBaseURL: %v", err)
+	}
+
+	original, _ := url.Parse(definition.Categories[0].URL)
+	want := "http://127.0.0.1:8080/store" + original.RequestURI()
+	if override.Categories[0].URL != want {
+		t.Errorf("got %q, want %q", override.Categories[0].URL, want)
+	}
+	if definition.Categories[0].URL == override.Categories[0].URL {
+		t.Error("WithBaseURL modified the original definition")
+	}
+
+	if _, err := ApplyBaseURLOverrides([]*SiteDefinition{definition}, map[string]string{"unknown": "http://localhost"}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func pagedProducts(page int) []fakestore.Product {
+	return []fakestore.Product{
+		{Name: fmt.Sprintf("Camiseta Página %d A", page), Price: "R$ 49,90", Image: "/img/a.jpg", Path: fmt.Sprintf("/p/%d-a", page)},
+		{Name: fmt.Sprintf("Camiseta Página %d B", page), Price: "R$ 59,90", Image: "/img/b.jpg", Path: fmt.Sprintf("/p/%d-b", page)},
+	}
+}
+
+func TestScrapeCategoryFollowsNextPages(t *testing.T) {
+	var definition *SiteDefinition
+	for _, candidate := range loadTestDefinitions(t) {
+		if candidate.Pagination.Mode == PaginationNext {
+			definition = candidate
+			break
+		}
+	}
+	if definition == nil {
+		t.Skip("no site definition uses next page pagination")
+	}
+	definition.Pagination.Delay = 0
+	definition.Pagination.MaxPages = 5
+
+	tests := []struct {
+		name        string
+		maxProducts int
+		maxPages    int
+		want        int
+		unvisited   string
+	}{
+		{name: "stops at already visited page", maxProducts: 50, maxPages: 5, want: 6},
+		{name: "stops at target count", maxProducts: 3, maxPages: 5, want: 3, unvisited: "/list/3"},
+		{name: "stops at max pages", maxProducts: 50, maxPages: 2, want: 4, unvisited: "/list/3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := fakestore.New()
+			defer store.Close()
+
+			markup := fakestore.Markup(definition.Source)
+			store.AddListing("/list/1", fakestore.Listing{Markup: markup, Products: pagedProducts(1), Next: "/list/2#top"})
+			store.AddListing("/list/2", fakestore.Listing{Markup: markup, Products: pagedProducts(2), Next: "3"})
+			store.AddListing("/list/3", fakestore.Listing{Markup: markup, Products: pagedProducts(3), Next: "/list/1"})
+
+			paged := *definition
+			paged.BaseURL = store.URL()
+			paged.Pagination.MaxPages = tt.maxPages
+
+			scraper := NewDefinitionScraper(&paged, NewHTTPFetcher(nil, ""), &config.Config{MaxProducts: tt.maxProducts}, testLogger())
+			products, _, err := scraper.scrapeCategory(context.Background(), CategoryDefinition{Name: "camisetas", URL: store.URL() + "/list/1"})
+			if err != nil {
+				t.Fatalf("scrapeCategory: %v", err)
+			}
+
+			if len(products) != tt.want {
+				t.Errorf("got %d products, want %d", len(products), tt.want)
+			}
+			if hits := store.Hits("/list/1"); hits != 1 {
+				t.Errorf("first page fetched %d times, want 1", hits)
+			}
+			if tt.unvisited != "" && store.Hits(tt.unvisited) != 0 {
+				t.Errorf("%s should not have been fetched", tt.unvisited)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"shinewardrobe-scraper/internal/browser"
+
+	"github.com/chromedp/chromedp"
+)
+
+// PageFetcher returns the rendered HTML of a page. It separates loading pages
+// from parsing them so scrapers can run against saved fixtures.
+type PageFetcher interface {
+	FetchPage(ctx context.Context, pageURL string, page PageDefinition) (string, error)
+	// FetchScrolled loads a page with infinite loading, scrolling until enough
+	// items are rendered or scrolling stops adding new ones.
+	FetchScrolled(ctx context.Context, pageURL string, page PageDefinition, scroll ScrollOptions) (string, error)
+}
+
+type ScrollOptions struct {
+	ItemSelector string
+	Target       int
+	MaxScrolls   int
+	Pause        time.Duration
+}
+
+type BrowserFetcher struct {
+	browser *browser.Pool
+}
+
+func NewBrowserFetcher(pool *browser.Pool) *BrowserFetcher {
+	return &BrowserFetcher{browser: pool}
+}
+
+func (f *BrowserFetcher) FetchPage(ctx context.Context, pageURL string, page PageDefinition) (string, error) {
+	return f.FetchScrolled(ctx, pageURL, page, ScrollOptions{})
+}
+
+func (f *BrowserFetcher) FetchScrolled(ctx context.Context, pageURL string, page PageDefinition, scroll ScrollOptions) (string, error) {
+	tabCtx, cancel, err := f.browser.NewTab(ctx, categoryTabTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	response, err := chromedp.RunResponse(tabCtx, chromedp.Navigate(pageURL))
+	if err != nil {
+		return "", fmt.Errorf("failed to load page: %w", err)
+	}
+	if response != nil && response.Status >= 400 {
+		return "", fmt.Errorf("failed to load page: HTTP %d", response.Status)
+	}
+
+	err = chromedp.Run(tabCtx,
+		chromedp.Sleep(page.RenderDelay),
+		chromedp.WaitVisible(page.WaitSelector, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to load page: %w", err)
+	}
+
+	if scroll.MaxScrolls > 0 {
+		if err := scrollPage(tabCtx, scroll); err != nil {
+			return "", fmt.Errorf("failed to scroll page: %w", err)
+		}
+	}
+
+	var htmlContent string
+	if err := chromedp.Run(tabCtx, chromedp.InnerHTML("html", &htmlContent)); err != nil {
+		return "", fmt.Errorf("failed to read page: %w", err)
+	}
+
+	return htmlContent, nil
+}
+
+func scrollPage(ctx context.Context, scroll ScrollOptions) error {
+	if scroll.Pause <= 0 {
+		scroll.Pause = time.Second
+	}
+	countScript := fmt.Sprintf("document.querySelectorAll(%q).length", scroll.ItemSelector)
+
+	var count int
+	if err := chromedp.Run(ctx, chromedp.Evaluate(countScript, &count)); err != nil {
+		return err
+	}
+
+	for i := 0; i < scroll.MaxScrolls && count < scroll.Target; i++ {
+		previous := count
+		err := chromedp.Run(ctx,
+			chromedp.Evaluate("window.scrollTo(0, document.body.scrollHeight)", nil),
+			chromedp.Sleep(scroll.Pause),
+			chromedp.Evaluate(countScript, &count),
+		)
+		if err != nil {
+			return err
+		}
+		if count == previous {
+			break
+		}
+	}
+
+	return nil
+}
+
+// FixtureFetcher serves pages saved on disk instead of loading them, keyed by
+// URL. See FixturePath for the layout used by the fixtures command.
+type FixtureFetcher struct {
+	pages map[string]string
+}
+
+func NewFixtureFetcher(dir string, definition *SiteDefinition) *FixtureFetcher {
+	pages := map[string]string{}
+	for _, category := range definition.Categories {
+		pages[category.URL] = FixturePath(dir, definition.Source, category.Name)
+	}
+	return &FixtureFetcher{pages: pages}
+}
+
+func (f *FixtureFetcher) FetchPage(ctx context.Context, pageURL string, page PageDefinition) (string, error) {
+	path, exists := f.pages[pageURL]
+	if !exists {
+		return "", fmt.Errorf("no fixture for %s", pageURL)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	return string(data), nil
+}
+
+func (f *FixtureFetcher) FetchScrolled(ctx context.Context, pageURL string, page PageDefinition, scroll ScrollOptions) (string, error) {
+	return f.FetchPage(ctx, pageURL, page)
+}
+
+func FixturePath(dir, source, category string) string {
+	return filepath.Join(dir, source, category+".html")
+}
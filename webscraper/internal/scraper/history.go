@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"shinewardrobe-scraper/internal/runs"
+
+	"github.com/sirupsen/logrus"
+)
+
+// siteRun is what the scrape of a site needs to know about the run it is part
+// of.
+type siteRun struct {
+	id      string
+	trigger string
+	// categories limits the run to some categories of the site.
+	categories []string
+	tiers      *priceTiers
+	timeout    time.Duration
+}
+
+// runConfig is the snapshot of the settings stored with every site run. It
+// leaves out URLs and credentials.
+type runConfig struct {
+	MaxProducts            int    `json:"max_products"`
+	Concurrency            int    `json:"concurrency"`
+	Timeout                string `json:"timeout"`
+	LockWait               string `json:"lock_wait"`
+	FailOnPartial          bool   `json:"fail_on_partial"`
+	CatalogAPI             bool   `json:"catalog_api"`
+	DetailEnabled          bool   `json:"detail_enabled"`
+	DetailMaxPerRun        int    `json:"detail_max_per_run"`
+	IncludeKids            bool   `json:"include_kids"`
+	TierMode               string `json:"tier_mode"`
+	UnavailableAfterMisses int    `json:"unavailable_after_misses"`
+}
+
+// startRecord records the start of the scrape of a site. Failing to record
+// does not stop the scrape, so it only logs and returns nil.
+func (s *Service) startRecord(ctx context.Context, scraper SiteScraper, run siteRun, logger *logrus.Entry) *runs.Run {
+	snapshot, err := json.Marshal(runConfig{
+		MaxProducts:            s.config.MaxProducts,
+		Concurrency:            s.config.Concurrency,
+		Timeout:                run.timeout.String(),
+		LockWait:               s.config.LockWait.String(),
+		FailOnPartial:          s.config.FailOnPartial,
+		CatalogAPI:             s.config.CatalogAPI,
+		DetailEnabled:          s.config.DetailEnabled,
+		DetailMaxPerRun:        s.config.DetailMaxPerRun,
+		IncludeKids:            s.config.IncludeKids,
+		TierMode:               s.tiers.Mode,
+		UnavailableAfterMisses: s.config.UnavailableAfterMisses,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to encode run config")
+	}
+
+	record := &runs.Run{
+		RunID:      run.id,
+		Trigger:    run.trigger,
+		Source:     scraper.GetSource(),
+		Categories: run.categories,
+		StartedAt:  time.Now(),
+		Config:     snapshot,
+	}
+	if err := s.runs.Start(ctx, record); err != nil {
+		logger.WithError(err).Warn("Failed to record run start")
+		return nil
+	}
+	return record
+}
+
+// finishRecord stores the result of the scrape of a site.
+func (s *Service) finishRecord(record *runs.Run, result SiteResult, logger *logrus.Entry) {
+	if record == nil {
+		return
+	}
+
+	record.Status = result.Status()
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+	}
+	record.Found = result.Found
+	record.Inserted = result.Inserted
+	record.Updated = result.Updated
+	record.Rejected = result.Rejected
+	record.Unavailable = result.Unavailable
+
+	// The run context may be done by now, which must not lose the record.
+	if err := s.runs.Finish(context.Background(), record); err != nil {
+		logger.WithError(err).Warn("Failed to record run end")
+	}
+}
@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// siteLockNamespace is the first key of the advisory locks taken for sites,
+// so they cannot collide with locks taken by other users of the database. The
+// second key is the hash of the site source.
+const siteLockNamespace = 73170
+
+// siteLockPoll is how often a run waiting for a site lock tries again.
+const siteLockPoll = 5 * time.Second
+
+// siteLock is a Postgres advisory lock held by a site run. Advisory locks
+// belong to a session, so the lock keeps its own connection until released,
+// and Postgres drops it if the process dies.
+type siteLock struct {
+	conn   *sql.Conn
+	source string
+}
+
+// lockSite takes the lock of a site so that only one run, in this process or
+// in another replica, scrapes it at a time. When another run holds the lock
+// it waits up to the configured lock wait and returns nil if the lock is still
+// held by then, in which case the run should be skipped.
+func (s *Service) lockSite(ctx context.Context, source string, logger *logrus.Entry) (*siteLock, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection for the site lock: %w", err)
+	}
+
+	start := time.Now()
+	deadline := start.Add(s.config.LockWait)
+	for attempt := 0; ; attempt++ {
+		var acquired bool
+		err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, siteLockNamespace, source).Scan(&acquired)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to take the site lock: %w", err)
+		}
+		if acquired {
+			if attempt > 0 {
+				logger.WithField("waited", time.Since(start).String()).Info("Site lock acquired")
+			}
+			return &siteLock{conn: conn, source: source}, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			conn.Close()
+			return nil, nil
+		}
+		if attempt == 0 {
+			logger.WithField("lock_wait", s.config.LockWait.String()).Info("Another run is scraping the site, waiting for its lock")
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(min(siteLockPoll, time.Until(deadline))):
+		}
+	}
+}
+
+// release unlocks the site and returns the connection to the pool.
+func (l *siteLock) release() error {
+	defer l.conn.Close()
+
+	var released bool
+	err := l.conn.QueryRowContext(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, siteLockNamespace, l.source).Scan(&released)
+	if err == nil && !released {
+		err = fmt.Errorf("site lock of %s was not held", l.source)
+	}
+	if err != nil {
+		// Ending the session is the other way to drop the lock, so the
+		// connection must not go back to the pool.
+		l.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		return fmt.Errorf("failed to release the site lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"shinewardrobe-scraper/internal/runs"
+)
+
+// SiteResult is the outcome of scraping one site.
+type SiteResult struct {
+	Site   string
+	Source string
+	// Found counts the items the site listed, Rejected the ones left out
+	// before saving, so Found = Inserted + Updated + Rejected.
+	Found       int
+	Inserted    int
+	Updated     int
+	Rejected    int
+	Unavailable int
+	Duration    time.Duration
+	// Capped names the categories whose listing was cut short, which skips
+	// availability reconciliation.
+	Capped []string
+	// Err is a *PartialScrapeError when only some categories failed.
+	Err error
+	// Skipped is set when another run held the site lock.
+	Skipped bool
+}
+
+// Saved counts the products inserted or updated.
+func (r SiteResult) Saved() int {
+	return r.Inserted + r.Updated
+}
+
+// Partial reports whether the site failed only for some categories, so the
+// products of the others were saved.
+func (r SiteResult) Partial() bool {
+	var partial *PartialScrapeError
+	return errors.As(r.Err, &partial)
+}
+
+// Status returns the status recorded in the run history.
+func (r SiteResult) Status() string {
+	switch {
+	case r.Skipped:
+		return runs.StatusSkipped
+	case r.Partial():
+		return runs.StatusPartial
+	case r.Err != nil:
+		return runs.StatusFailed
+	default:
+		return runs.StatusSucceeded
+	}
+}
+
+// RunReport is the outcome of a run, with the result of every site it
+// scraped.
+type RunReport struct {
+	RunID    string
+	Trigger  string
+	Sites    []SiteResult
+	Duration time.Duration
+}
+
+// Saved counts the products saved across sites.
+func (r *RunReport) Saved() int {
+	saved := 0
+	for _, site := range r.Sites {
+		saved += site.Saved()
+	}
+	return saved
+}
+
+// Err joins the errors of the sites that failed. Sites that failed only for
+// some categories count as failed when failOnPartial is set; skipped sites
+// never do.
+func (r *RunReport) Err(failOnPartial bool) error {
+	var errs []error
+	for _, site := range r.Sites {
+		if site.Err == nil || site.Partial() && !failOnPartial {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", site.Source, site.Err))
+	}
+	return errors.Join(errs...)
+}
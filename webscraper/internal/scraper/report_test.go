@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"shinewardrobe-scraper/internal/runs"
+)
+
+func TestRunReportErrFollowsPartialPolicy(t *testing.T) {
+	partial := &PartialScrapeError{Site: "Renner", Failed: map[string]error{"dresses": errors.New("timeout")}}
+	report := &RunReport{Sites: []SiteResult{
+		{Source: "zara", Inserted: 3, Updated: 2},
+		{Source: "renner", Inserted: 1, Err: partial},
+		{Source: "cea", Skipped: true},
+	}}
+
+	if err := report.Err(false); err != nil {
+		t.Errorf("Err(false) = %v, want nil", err)
+	}
+	err := report.Err(true)
+	if !errors.Is(err, partial) || !strings.HasPrefix(err.Error(), "renner: ") {
+		t.Errorf("Err(true) = %v, want the renner partial error", err)
+	}
+
+	failed := errors.New("failed to scrape Riachuelo")
+	report.Sites = append(report.Sites, SiteResult{Source: "riachuelo", Err: failed})
+	if err := report.Err(false); !errors.Is(err, failed) || errors.Is(err, partial) {
+		t.Errorf("Err(false) = %v, want only the riachuelo error", err)
+	}
+	if err := report.Err(true); !errors.Is(err, failed) || !errors.Is(err, partial) {
+		t.Errorf("Err(true) = %v, want both errors", err)
+	}
+
+	if saved := report.Saved(); saved != 6 {
+		t.Errorf("Saved() = %d, want 6", saved)
+	}
+
+	statuses := []string{runs.StatusSucceeded, runs.StatusPartial, runs.StatusSkipped, runs.StatusFailed}
+	for i, site := range report.Sites {
+		if status := site.Status(); status != statuses[i] {
+			t.Errorf("%s: Status() = %q, want %q", site.Source, status, statuses[i])
+		}
+	}
+}
@@ -3,22 +3,41 @@ package scraper
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"shinewardrobe-scraper/internal/browser"
+	"shinewardrobe-scraper/internal/climate"
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/fx"
+	"shinewardrobe-scraper/internal/pricing"
+	"shinewardrobe-scraper/internal/runs"
+	"shinewardrobe-scraper/internal/taxonomy"
+	"shinewardrobe-scraper/internal/vtex"
+
 	"github.com/PuerkitoBio/goquery"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type Product struct {
 	Name         string
 	Brand        string
+	// Seller is the marketplace seller, empty for stores selling their own
+	// stock.
+	Seller       string
 	Category     string
 	Subcategory  string
 	Price        float64
 	OriginalPrice *float64
+	Currency     string
 	ImageURL     string
 	ProductURL   string
 	Description  string
@@ -26,177 +45,602 @@ type Product struct {
 	Colors       []string
 	IsLuxury     bool
 	IsEconomic   bool
+	TierRule     string
 	Source       string
 	Gender       string
 	Season       string
 	Weather      []string
+
+	// Available is false for sold-out products, which are still saved so
+	// their price history records them.
+	Available bool
+
+	// GenderConfidence goes from 0, when Gender is unisex for lack of any
+	// hint, to 1. Kids marks children's clothing.
+	GenderConfidence float64
+	Kids             bool
+
+	// MinTemp and MaxTemp bound the temperatures, in °C, the product is
+	// comfortable in.
+	MinTemp int
+	MaxTemp int
+
+	Material   string
+	Images     []string
+	Stock      []SizeStock
+	DetailedAt *time.Time
+
+	// Installments is the longest plan shown, CashPrice the Pix, boleto or
+	// "à vista" price when lower than Price.
+	Installments *pricing.Installments
+	CashPrice    *float64
+
+	// FieldSources records which extraction strategy produced each field.
+	FieldSources map[string]Strategy
+}
+
+// SizeStock reports whether a size can be bought. Quantity is only set when
+// the store exposes it.
+type SizeStock struct {
+	Size      string `json:"size"`
+	Available bool   `json:"available"`
+	Quantity  *int   `json:"quantity,omitempty"`
+}
+
+// DetailEnricher is implemented by scrapers that can visit a product page to
+// fill in real sizes, colors, description, material and images.
+type DetailEnricher interface {
+	HasDetailPages() bool
+	EnrichProduct(ctx context.Context, product *Product) error
 }
 
 type Service struct {
 	db     *sql.DB
+	config *config.Config
 	logger *logrus.Entry
 	sites  []SiteScraper
+	rates  fx.Provider
+	tiers  *TierConfig
+	runs   *runs.Repository
+	// slots bounds the sites scraped at once across ScrapeAll and
+	// ScrapeSite calls.
+	slots chan struct{}
 }
 
 type SiteScraper interface {
 	GetName() string
+	GetSource() string
+	GetCategories() []string
 	ScrapeProducts(ctx context.Context) ([]Product, error)
+	// ScrapeCategories scrapes only the named categories.
+	ScrapeCategories(ctx context.Context, categories []string) ([]Product, ListingStats, error)
+}
+
+// ListingStats describes what a scrape left out of the listings.
+type ListingStats struct {
+	// Discarded counts listed items left out for lacking a name or price or
+	// for repeating a product already listed in the category.
+	Discarded int
+	// Capped names the categories whose listing was cut short by
+	// SCRAPER_MAX_PRODUCTS or the pagination limit. Products past the cap
+	// were not looked for, so they must not be taken for missing.
+	Capped []string
+}
+
+func (s *ListingStats) add(other ListingStats) {
+	s.Discarded += other.Discarded
+	s.Capped = append(s.Capped, other.Capped...)
+}
+
+// PartialScrapeError is returned by a SiteScraper when some categories failed
+// but the products from the remaining ones are still usable.
+type PartialScrapeError struct {
+	Site   string
+	Failed map[string]error
+}
+
+func (e *PartialScrapeError) Error() string {
+	categories := make([]string, 0, len(e.Failed))
+	for category, err := range e.Failed {
+		categories = append(categories, fmt.Sprintf("%s: %v", category, err))
+	}
+	sort.Strings(categories)
+	return fmt.Sprintf("%s: %d categories failed (%s)", e.Site, len(e.Failed), strings.Join(categories, "; "))
 }
 
-func NewService(db *sql.DB, logger *logrus.Entry) *Service {
+const categoryTabTimeout = 90 * time.Second
+
+// DefaultSiteTimeout bounds the scraping of a site in ScrapeAll.
+const DefaultSiteTimeout = 5 * time.Minute
+
+// NewService builds the scrapers for definitions. rates may be nil, in which
+// case products priced in other currencies than BRL get no price tier.
+func NewService(db *sql.DB, pool *browser.Pool, cfg *config.Config, definitions []*SiteDefinition, rates fx.Provider, tiers *TierConfig, logger *logrus.Entry) *Service {
 	service := &Service{
 		db:     db,
+		config: cfg,
 		logger: logger,
+		rates:  rates,
+		tiers:  tiers,
+		runs:   runs.NewRepository(db),
+		slots:  make(chan struct{}, cfg.Concurrency),
 	}
 
-	service.sites = []SiteScraper{
-		NewZaraScraper(logger),
-		NewRennerScraper(logger),
-		NewCASScraper(logger),
-		NewAmericanasScraper(logger),
+	fetcher := NewBrowserFetcher(pool)
+	httpClient := &http.Client{Timeout: cfg.CatalogTimeout}
+	for _, definition := range definitions {
+		if definition.Catalog != nil && cfg.CatalogAPI {
+			client := vtex.NewClient(definition.BaseURL, httpClient, cfg.UserAgent)
+			service.sites = append(service.sites, NewVTEXScraper(definition, client, cfg, logger))
+			continue
+		}
+		service.sites = append(service.sites, NewDefinitionScraper(definition, fetcher, cfg, logger))
 	}
 
 	return service
 }
 
-func (s *Service) ScrapeAll(ctx context.Context) error {
-	s.logger.Info("Starting product scraping from all sites...")
+// ScrapeAll scrapes every site. trigger says what started the run and is
+// recorded in the run history. The error joins the errors of the sites that
+// failed, counting partial failures only when SCRAPER_FAIL_ON_PARTIAL is set.
+func (s *Service) ScrapeAll(ctx context.Context, trigger string) (*RunReport, error) {
+	s.logger.WithFields(logrus.Fields{
+		"sites":       len(s.sites),
+		"concurrency": s.config.Concurrency,
+	}).Info("Starting product scraping from all sites...")
+
+	start := time.Now()
+	run := siteRun{id: uuid.NewString(), trigger: trigger, tiers: s.priceTiers(ctx), timeout: DefaultSiteTimeout}
+	report := &RunReport{RunID: run.id, Trigger: trigger, Sites: make([]SiteResult, len(s.sites))}
+
+	var wg sync.WaitGroup
+	for i, scraper := range s.sites {
+		wg.Add(1)
+		go func(i int, scraper SiteScraper) {
+			defer wg.Done()
+
+			// Slots are shared with scheduled jobs, so waiting for one
+			// must end with the run.
+			select {
+			case s.slots <- struct{}{}:
+			case <-ctx.Done():
+				report.Sites[i] = SiteResult{
+					Site:   scraper.GetName(),
+					Source: scraper.GetSource(),
+					Err:    fmt.Errorf("not started: %w", ctx.Err()),
+				}
+				return
+			}
+			defer func() { <-s.slots }()
+
+			report.Sites[i] = s.scrapeSite(ctx, scraper, run)
+		}(i, scraper)
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+
+	for i, result := range report.Sites {
+		s.logResult(result, logrus.Fields{"site_index": i})
+	}
 
-	totalProducts := 0
-	for _, scraper := range s.sites {
-		products, err := s.scrapeFromSite(ctx, scraper)
-		if err != nil {
-			s.logger.WithError(err).WithField("site", scraper.GetName()).Error("Failed to scrape site")
-			continue
+	err := report.Err(s.config.FailOnPartial)
+	fields := logrus.Fields{
+		"run_id":         run.id,
+		"total_products": report.Saved(),
+		"duration":       report.Duration.String(),
+	}
+	if err != nil {
+		s.logger.WithFields(fields).WithError(err).Error("Scraping completed with failures")
+	} else {
+		s.logger.WithFields(fields).Info("Scraping completed")
+	}
+	return report, err
+}
+
+// Sites returns the scrapers of the service.
+func (s *Service) Sites() []SiteScraper {
+	return s.sites
+}
+
+// ScrapeSite scrapes the site with the given source, or only the named
+// categories of it when categories is not empty. Scraping stops after
+// timeout; detail enrichment has its own timeout. The report and error follow
+// ScrapeAll.
+func (s *Service) ScrapeSite(ctx context.Context, source string, categories []string, timeout time.Duration, trigger string) (*RunReport, error) {
+	var scraper SiteScraper
+	for _, site := range s.sites {
+		if site.GetSource() == source {
+			scraper = site
 		}
+	}
+	if scraper == nil {
+		return nil, fmt.Errorf("unknown site %q", source)
+	}
 
-		saved, err := s.saveProducts(products)
-		if err != nil {
-			s.logger.WithError(err).WithField("site", scraper.GetName()).Error("Failed to save products")
-			continue
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	run := siteRun{id: uuid.NewString(), trigger: trigger, categories: categories, tiers: s.priceTiers(ctx), timeout: timeout}
+	result := s.scrapeSite(ctx, scraper, run)
+	s.logResult(result, logrus.Fields{"categories": len(categories)})
+
+	report := &RunReport{RunID: run.id, Trigger: trigger, Sites: []SiteResult{result}, Duration: result.Duration}
+	return report, report.Err(s.config.FailOnPartial)
+}
+
+func (s *Service) logResult(result SiteResult, fields logrus.Fields) {
+	logger := s.logger.WithFields(fields).WithFields(logrus.Fields{
+		"site":        result.Site,
+		"products":    result.Saved(),
+		"unavailable": result.Unavailable,
+		"duration":    result.Duration.String(),
+	})
+	switch {
+	case result.Err != nil:
+		logger.WithError(result.Err).Error("Site finished with errors")
+	case result.Skipped:
+		logger.Info("Site skipped")
+	default:
+		logger.Info("Site finished")
+	}
+}
+
+// scrapeSite scrapes, saves and reconciles one site while holding its lock,
+// and records the outcome in the run history. Runs limited to some categories
+// or whose listings were capped skip reconciliation, since the products of the
+// other categories, or past the cap, were not looked for.
+func (s *Service) scrapeSite(ctx context.Context, scraper SiteScraper, run siteRun) (result SiteResult) {
+	siteLogger := s.logger.WithFields(logrus.Fields{"site": scraper.GetName(), "run_id": run.id})
+	result = SiteResult{Site: scraper.GetName(), Source: scraper.GetSource()}
+
+	start := time.Now()
+	record := s.startRecord(ctx, scraper, run, siteLogger)
+	defer func() {
+		result.Duration = time.Since(start)
+		s.finishRecord(record, result, siteLogger)
+	}()
+
+	lock, err := s.lockSite(ctx, scraper.GetSource(), siteLogger)
+	if err != nil {
+		siteLogger.WithError(err).Error("Failed to lock site")
+		result.Err = err
+		return result
+	}
+	if lock == nil {
+		siteLogger.Info("Another run is scraping the site, skipping this run")
+		result.Skipped = true
+		return result
+	}
+	defer func() {
+		if err := lock.release(); err != nil {
+			siteLogger.WithError(err).Error("Failed to unlock site")
 		}
+	}()
+
+	siteStart := time.Now()
+
+	products, err := s.scrapeFromSite(ctx, scraper, run, &result)
+	complete := err == nil
+	if err != nil {
+		result.Err = err
+		var partial *PartialScrapeError
+		if !errors.As(err, &partial) {
+			siteLogger.WithError(err).Error("Failed to scrape site")
+			return result
+		}
+		siteLogger.WithError(err).Warn("Site scraped partially, skipping availability reconciliation")
+	}
+
+	inserted, updated, err := s.saveProducts(products)
+	if err != nil {
+		siteLogger.WithError(err).Error("Failed to save products")
+		result.Err = err
+		return result
+	}
+
+	result.Inserted = inserted
+	result.Updated = updated
+	siteLogger.WithFields(logrus.Fields{
+		"products": result.Saved(),
+		"inserted": inserted,
+	}).Info("Successfully scraped and saved products")
+
+	if !complete || len(run.categories) > 0 {
+		return result
+	}
+	if len(result.Capped) > 0 {
+		siteLogger.WithField("capped", result.Capped).Warn("Listings were capped, skipping availability reconciliation")
+		return result
+	}
+	if len(products) == 0 {
+		siteLogger.Warn("Site returned no products, skipping availability reconciliation")
+		return result
+	}
 
-		totalProducts += saved
-		s.logger.WithFields(logrus.Fields{
-			"site": scraper.GetName(),
-			"products": saved,
-		}).Info("Successfully scraped and saved products")
+	unavailable, err := s.reconcileAvailability(scraper.GetSource(), siteStart)
+	if err != nil {
+		siteLogger.WithError(err).Error("Failed to reconcile product availability")
+		result.Err = err
+		return result
+	}
+	result.Unavailable = unavailable
+	if unavailable > 0 {
+		siteLogger.WithField("unavailable", unavailable).Info("Marked missing products as unavailable")
 	}
 
-	s.logger.WithField("total_products", totalProducts).Info("Scraping completed")
-	return nil
+	return result
 }
 
-func (s *Service) scrapeFromSite(ctx context.Context, scraper SiteScraper) ([]Product, error) {
-	timeout := 5 * time.Minute
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+// scrapeFromSite returns the products to save, each once, and counts in result
+// the items the site listed and the ones rejected, so that found = inserted +
+// updated + rejected once the products are saved.
+func (s *Service) scrapeFromSite(ctx context.Context, scraper SiteScraper, run siteRun, result *SiteResult) ([]Product, error) {
+	listingCtx, cancel := context.WithTimeout(ctx, run.timeout)
 	defer cancel()
 
 	s.logger.WithField("site", scraper.GetName()).Info("Scraping products...")
 	
-	products, err := scraper.ScrapeProducts(ctx)
+	products, stats, err := scraper.ScrapeCategories(listingCtx, run.categories)
+	result.Found = len(products) + stats.Discarded
+	result.Rejected = stats.Discarded
+	result.Capped = stats.Capped
 	if err != nil {
-		return nil, fmt.Errorf("failed to scrape %s: %w", scraper.GetName(), err)
+		var partial *PartialScrapeError
+		if !errors.As(err, &partial) {
+			return nil, fmt.Errorf("failed to scrape %s: %w", scraper.GetName(), err)
+		}
 	}
 
+	// A product listed in several categories is saved once.
+	unique := dedupeProducts(products)
+	result.Rejected += len(products) - len(unique)
+	products = unique
+
+	// Product pages may categorize what the listing could not, so the detail
+	// pass runs before the products are filtered and tiered.
+	s.enrichProducts(ctx, scraper, products)
+
+	if !s.config.IncludeKids {
+		kept := dropKids(products)
+		if dropped := len(products) - len(kept); dropped > 0 {
+			s.logger.WithField("site", scraper.GetName()).WithField("products", dropped).Info("Skipped children's products")
+			result.Rejected += dropped
+		}
+		products = kept
+	}
+
+	unconverted := 0
 	for i := range products {
-		s.categorizeProduct(&products[i])
+		price, convErr := s.priceInBRL(ctx, products[i])
+		if convErr != nil {
+			unconverted++
+			continue
+		}
+		run.tiers.assign(&products[i], price)
+	}
+	if unconverted > 0 {
+		s.logger.WithField("site", scraper.GetName()).WithField("products", unconverted).Warn("No FX rate for product currency, skipping price tiers")
 	}
 
-	return products, nil
+	return products, err
 }
 
-func (s *Service) categorizeProduct(product *Product) {
-	economicThresholds := map[string]float64{
-		"shirt":     80.0,  
-		"pants":     150.0, 
-		"dress":     120.0, 
-		"shoes":     200.0, 
-		"jacket":    250.0, 
-		"accessory": 50.0,  
+// dedupeProducts keeps the first product of every URL.
+func dedupeProducts(products []Product) []Product {
+	seen := map[string]bool{}
+	kept := products[:0]
+	for _, product := range products {
+		if !seen[product.ProductURL] {
+			seen[product.ProductURL] = true
+			kept = append(kept, product)
+		}
 	}
+	return kept
+}
 
-	luxuryThresholds := map[string]float64{
-		"shirt":     300.0, 
-		"pants":     500.0, 
-		"dress":     600.0, 
-		"shoes":     800.0, 
-		"jacket":    800.0, 
-		"accessory": 200.0, 
+// dropKids removes children's clothing, which the wardrobe does not cover.
+func dropKids(products []Product) []Product {
+	kept := products[:0]
+	for _, product := range products {
+		if !product.Kids {
+			kept = append(kept, product)
+		}
 	}
+	return kept
+}
 
-	category := strings.ToLower(product.Category)
-	
-	if threshold, exists := economicThresholds[category]; exists && product.Price <= threshold {
-		product.IsEconomic = true
-	}
+// priceInBRL normalizes the product price so the tier thresholds, which are
+// in BRL, apply to every source.
+func (s *Service) priceInBRL(ctx context.Context, product Product) (float64, error) {
+	return fx.Convert(ctx, s.rates, product.Price, firstNonEmpty(product.Currency, DefaultCurrency), "BRL")
+}
 
-	if threshold, exists := luxuryThresholds[category]; exists && product.Price >= threshold {
-		product.IsLuxury = true
+// priceTiers prepares the tier rules for a run. In percentile mode it falls
+// back to the configured rules when the percentiles cannot be computed.
+func (s *Service) priceTiers(ctx context.Context) *priceTiers {
+	tiers := newPriceTiers(s.tiers)
+	if s.tiers.Mode != TierModePercentile {
+		return tiers
 	}
 
-	if !product.IsEconomic && !product.IsLuxury {
-		if product.Price <= 100.0 {
-			product.IsEconomic = true
-		} else if product.Price >= 400.0 {
-			product.IsLuxury = true
-		}
+	if err := tiers.loadPercentiles(ctx, s.db); err != nil {
+		s.logger.WithError(err).Warn("Failed to compute price percentiles, using tier rules")
+		return tiers
 	}
+	s.logger.WithField("categories", len(tiers.percentiles)).Info("Price percentiles computed")
+	return tiers
 }
 
-func (s *Service) saveProducts(products []Product) (int, error) {
+// saveProducts upserts products and returns how many were inserted and how
+// many updated.
+func (s *Service) saveProducts(products []Product) (int, int, error) {
 	if len(products) == 0 {
-		return 0, nil
+		return 0, 0, nil
 	}
 
-	query := `
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsertStmt, err := tx.Prepare(`
 		INSERT INTO products (
-			name, brand, category, subcategory, price, original_price,
+			name, brand, seller, category, subcategory, price, original_price,
 			image_url, product_url, description, sizes, colors,
 			is_luxury, is_economic, source, gender, season, weather,
-			scraped_at, created_at, updated_at
-		) VALUES `
-
-	values := []interface{}{}
-	placeholders := []string{}
-
-	for i, product := range products {
-		placeholder := fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			i*20+1, i*20+2, i*20+3, i*20+4, i*20+5, i*20+6,
-			i*20+7, i*20+8, i*20+9, i*20+10, i*20+11, i*20+12,
-			i*20+13, i*20+14, i*20+15, i*20+16, i*20+17, i*20+18,
-			i*20+19, i*20+20)
-		
-		placeholders = append(placeholders, placeholder)
-		
+			material, images, size_stock, detailed_at,
+			installment_count, installment_value, installment_interest_free, cash_price,
+			currency, tier_rule, gender_confidence, is_kids, min_temperature, max_temperature,
+			is_available, scraped_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36)
+		ON CONFLICT (product_url) DO UPDATE SET
+			brand = EXCLUDED.brand,
+			seller = EXCLUDED.seller,
+			category = EXCLUDED.category,
+			subcategory = EXCLUDED.subcategory,
+			price = EXCLUDED.price,
+			original_price = EXCLUDED.original_price,
+			currency = EXCLUDED.currency,
+			is_luxury = EXCLUDED.is_luxury,
+			is_economic = EXCLUDED.is_economic,
+			tier_rule = EXCLUDED.tier_rule,
+			gender = EXCLUDED.gender,
+			gender_confidence = EXCLUDED.gender_confidence,
+			is_kids = EXCLUDED.is_kids,
+			installment_count = EXCLUDED.installment_count,
+			installment_value = EXCLUDED.installment_value,
+			installment_interest_free = EXCLUDED.installment_interest_free,
+			cash_price = EXCLUDED.cash_price,
+			description = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.description ELSE EXCLUDED.description END,
+			sizes = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.sizes ELSE EXCLUDED.sizes END,
+			colors = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.colors ELSE EXCLUDED.colors END,
+			weather = CASE WHEN EXCLUDED.detailed_at IS NULL AND products.detailed_at IS NOT NULL THEN products.weather ELSE EXCLUDED.weather END,
+			season = CASE WHEN EXCLUDED.detailed_at IS NULL AND products.detailed_at IS NOT NULL THEN products.season ELSE EXCLUDED.season END,
+			min_temperature = CASE WHEN EXCLUDED.detailed_at IS NULL AND products.detailed_at IS NOT NULL THEN products.min_temperature ELSE EXCLUDED.min_temperature END,
+			max_temperature = CASE WHEN EXCLUDED.detailed_at IS NULL AND products.detailed_at IS NOT NULL THEN products.max_temperature ELSE EXCLUDED.max_temperature END,
+			material = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.material ELSE EXCLUDED.material END,
+			images = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.images ELSE EXCLUDED.images END,
+			size_stock = CASE WHEN EXCLUDED.detailed_at IS NULL THEN products.size_stock ELSE EXCLUDED.size_stock END,
+			detailed_at = COALESCE(EXCLUDED.detailed_at, products.detailed_at),
+			is_available = EXCLUDED.is_available,
+			missed_scrapes = 0,
+			scraped_at = EXCLUDED.scraped_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, xmax = 0`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prepare product upsert: %w", err)
+	}
+	defer upsertStmt.Close()
+
+	historyStmt, err := tx.Prepare(priceHistoryQuery)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to prepare price history insert: %w", err)
+	}
+	defer historyStmt.Close()
+
+	inserted, updated := 0, 0
+	for _, product := range products {
+		now := time.Now()
+
 		sizesJSON := fmt.Sprintf("[%s]", strings.Join(s.quoteStrings(product.Sizes), ","))
 		colorsJSON := fmt.Sprintf("[%s]", strings.Join(s.quoteStrings(product.Colors), ","))
 		weatherJSON := fmt.Sprintf("[%s]", strings.Join(s.quoteStrings(product.Weather), ","))
 
-		values = append(values,
-			product.Name, product.Brand, product.Category, product.Subcategory,
+		imagesJSON, stockJSON, err := detailJSON(product)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to encode details of %s: %w", product.ProductURL, err)
+		}
+
+		var installmentCount, installmentValue, installmentInterestFree interface{}
+		if product.Installments != nil {
+			installmentCount = product.Installments.Count
+			installmentValue = product.Installments.Value
+			installmentInterestFree = product.Installments.InterestFree
+		}
+
+		var productID string
+		// xmax is 0 on rows the upsert inserted rather than updated.
+		var isNew bool
+		err = upsertStmt.QueryRow(
+			product.Name, nullString(product.Brand), nullString(product.Seller), product.Category, product.Subcategory,
 			product.Price, product.OriginalPrice, product.ImageURL, product.ProductURL,
 			product.Description, sizesJSON, colorsJSON, product.IsLuxury,
 			product.IsEconomic, product.Source, product.Gender, product.Season,
-			weatherJSON, time.Now(), time.Now(), time.Now(),
-		)
+			weatherJSON, nullString(product.Material), imagesJSON, stockJSON, product.DetailedAt,
+			installmentCount, installmentValue, installmentInterestFree, product.CashPrice,
+			firstNonEmpty(product.Currency, DefaultCurrency), nullString(product.TierRule),
+			product.GenderConfidence, product.Kids, product.MinTemp, product.MaxTemp,
+			product.Available, now, now, now,
+		).Scan(&productID, &isNew)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to upsert product %s: %w", product.ProductURL, err)
+		}
+
+		if _, err := historyStmt.Exec(productID, product.Price, product.Available, now); err != nil {
+			return 0, 0, fmt.Errorf("failed to record price history for %s: %w", product.ProductURL, err)
+		}
+
+		if isNew {
+			inserted++
+		} else {
+			updated++
+		}
 	}
 
-	query += strings.Join(placeholders, ",")
-	query += ` ON CONFLICT (product_url) DO UPDATE SET
-		price = EXCLUDED.price,
-		original_price = EXCLUDED.original_price,
-		is_available = true,
-		scraped_at = EXCLUDED.scraped_at,
-		updated_at = EXCLUDED.updated_at`
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit products: %w", err)
+	}
 
-	result, err := s.db.Exec(query, values...)
-	if err != nil {
-		return 0, fmt.Errorf("failed to insert products: %w", err)
+	return inserted, updated, nil
+}
+
+// priceHistoryQuery appends a history row only when the price or availability
+// differs from the latest recorded entry for the product.
+const priceHistoryQuery = `
+	INSERT INTO product_price_history (product_id, price, is_available, recorded_at)
+	SELECT $1::uuid, $2::numeric(10,2), $3::boolean, $4::timestamp
+	WHERE NOT EXISTS (
+		SELECT 1 FROM (
+			SELECT price, is_available
+			FROM product_price_history
+			WHERE product_id = $1
+			ORDER BY recorded_at DESC
+			LIMIT 1
+		) latest
+		WHERE latest.price = $2::numeric(10,2) AND latest.is_available = $3::boolean
+	)`
+
+// detailJSON encodes the fields filled by the detail pass, returning NULLs for
+// products that were not enriched.
+func detailJSON(product Product) (interface{}, interface{}, error) {
+	var imagesJSON, stockJSON interface{}
+
+	if len(product.Images) > 0 {
+		encoded, err := json.Marshal(product.Images)
+		if err != nil {
+			return nil, nil, err
+		}
+		imagesJSON = string(encoded)
+	}
+
+	if len(product.Stock) > 0 {
+		encoded, err := json.Marshal(product.Stock)
+		if err != nil {
+			return nil, nil, err
+		}
+		stockJSON = string(encoded)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	return int(rowsAffected), nil
+	return imagesJSON, stockJSON, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
 }
 
 func (s *Service) quoteStrings(strs []string) []string {
@@ -208,84 +652,99 @@ func (s *Service) quoteStrings(strs []string) []string {
 }
 
 
-func extractPrice(priceText string) float64 {
-	cleaned := strings.ReplaceAll(priceText, "R$", "")
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
-	cleaned = strings.ReplaceAll(cleaned, ".", "")
-	cleaned = strings.ReplaceAll(cleaned, ",", ".")
-	cleaned = strings.TrimSpace(cleaned)
-
-	price, err := strconv.ParseFloat(cleaned, 64)
-	if err != nil {
-		return 0.0
-	}
-	return price
-}
+const (
+	defaultSizeSelector            = "[data-size], .size-option, .size-selector option"
+	defaultSizeUnavailableSelector = "[disabled], [aria-disabled='true'], .disabled, .unavailable, .out-of-stock"
+	defaultColorSelector           = "[data-color], .color-option, .color-name"
+)
 
-func extractSizes(doc *goquery.Document) []string {
-	sizes := []string{}
-	doc.Find("[data-size], .size-option, .size-selector option").Each(func(i int, s *goquery.Selection) {
+func extractSizes(doc *goquery.Document, selector, unavailableSelector, stockAttribute string) []SizeStock {
+	sizes := []SizeStock{}
+	seen := map[string]bool{}
+	doc.Find(firstNonEmpty(selector, defaultSizeSelector)).Each(func(i int, s *goquery.Selection) {
 		size := strings.TrimSpace(s.Text())
-		if size != "" && size != "Selecione" {
-			sizes = append(sizes, size)
+		if value, exists := s.Attr("data-size"); exists && strings.TrimSpace(value) != "" {
+			size = strings.TrimSpace(value)
 		}
+		if size == "" || size == "Selecione" || seen[size] {
+			return
+		}
+		seen[size] = true
+
+		stock := SizeStock{
+			Size:      size,
+			Available: !s.Is(firstNonEmpty(unavailableSelector, defaultSizeUnavailableSelector)),
+		}
+		if stockAttribute != "" {
+			if value, exists := s.Attr(stockAttribute); exists {
+				if quantity, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+					stock.Quantity = &quantity
+					stock.Available = stock.Available && quantity > 0
+				}
+			}
+		}
+
+		sizes = append(sizes, stock)
 	})
 	return sizes
 }
 
-func extractColors(doc *goquery.Document) []string {
+func extractColors(doc *goquery.Document, selector string) []string {
 	colors := []string{}
-	doc.Find("[data-color], .color-option, .color-name").Each(func(i int, s *goquery.Selection) {
+	seen := map[string]bool{}
+	doc.Find(firstNonEmpty(selector, defaultColorSelector)).Each(func(i int, s *goquery.Selection) {
 		color := strings.TrimSpace(s.Text())
-		if color != "" {
+		if color == "" {
+			color, _ = s.Attr("data-color")
+			color = strings.TrimSpace(color)
+		}
+		if color != "" && !seen[color] {
+			seen[color] = true
 			colors = append(colors, color)
 		}
 	})
 	return colors
 }
 
-func determineGender(productName, category string) string {
-	lowerName := strings.ToLower(productName + " " + category)
-	
-	femaleKeywords := []string{"feminino", "mulher", "blusa", "saia", "vestido", "salto"}
-	maleKeywords := []string{"masculino", "homem", "camisa", "gravata", "terno"}
-	
-	for _, keyword := range femaleKeywords {
-		if strings.Contains(lowerName, keyword) {
-			return "female"
-		}
-	}
-	
-	for _, keyword := range maleKeywords {
-		if strings.Contains(lowerName, keyword) {
-			return "male"
-		}
-	}
-	
-	return "unisex"
+// listingContext describes where a product was found: the listing page and
+// the category names it was listed under, most specific first.
+type listingContext struct {
+	URL        string
+	Breadcrumb []string
 }
 
-func determineWeatherSuitability(category, description string) []string {
-	lower := strings.ToLower(category + " " + description)
-	weather := []string{}
-	
-	if strings.Contains(lower, "verão") || strings.Contains(lower, "algodão") || 
-	   strings.Contains(lower, "leve") || strings.Contains(lower, "respirável") {
-		weather = append(weather, "hot", "sunny")
-	}
-	
-	if strings.Contains(lower, "inverno") || strings.Contains(lower, "lã") || 
-	   strings.Contains(lower, "casaco") || strings.Contains(lower, "jaqueta") {
-		weather = append(weather, "cold")
-	}
-	
-	if strings.Contains(lower, "impermeável") || strings.Contains(lower, "chuva") {
-		weather = append(weather, "rain")
-	}
-	
-	if len(weather) == 0 {
-		weather = []string{"sunny", "cloudy"}
+func (l listingContext) input(product *Product) taxonomy.Input {
+	return taxonomy.Input{
+		Name:        product.Name,
+		URL:         product.ProductURL,
+		ListingURL:  l.URL,
+		Breadcrumb:  l.Breadcrumb,
+		Description: product.Description,
 	}
-	
-	return weather
-}
\ No newline at end of file
+}
+
+// classifyProduct sets the category and subcategory from the shared taxonomy.
+func classifyProduct(product *Product, listing listingContext) {
+	result := taxonomy.Classify(listing.input(product))
+	product.Category, product.Subcategory = result.Category, result.Subcategory
+}
+
+// assessClimate sets the weather tags, comfortable temperatures and season.
+func assessClimate(model climate.Model, product *Product) {
+	suitability := model.Assess(climate.Garment{
+		Category:    product.Category,
+		Subcategory: product.Subcategory,
+		Name:        product.Name,
+		Description: product.Description,
+		Material:    product.Material,
+	})
+	product.Weather, product.Season = suitability.Weather, suitability.Season
+	product.MinTemp, product.MaxTemp = suitability.MinTemp, suitability.MaxTemp
+}
+
+// detectGender sets the gender, its confidence and whether the product is
+// children's clothing.
+func detectGender(product *Product, listing listingContext) {
+	result := taxonomy.DetectGender(listing.input(product))
+	product.Gender, product.GenderConfidence, product.Kids = result.Gender, result.Confidence, result.Kids
+}
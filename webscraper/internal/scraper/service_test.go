@@ -0,0 +1,164 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/runs"
+	"shinewardrobe-scraper/internal/taxonomy"
+)
+
+// stubScraper returns fixed products, as if every category was scraped.
+type stubScraper struct {
+	products  []Product
+	discarded int
+}
+
+func (s *stubScraper) GetName() string         { return "Stub" }
+func (s *stubScraper) GetSource() string       { return "stub" }
+func (s *stubScraper) GetCategories() []string { return nil }
+
+func (s *stubScraper) ScrapeProducts(ctx context.Context) ([]Product, error) {
+	return s.products, nil
+}
+
+func (s *stubScraper) ScrapeCategories(ctx context.Context, categories []string) ([]Product, ListingStats, error) {
+	return append([]Product(nil), s.products...), ListingStats{Discarded: s.discarded}, nil
+}
+
+func TestScrapeFromSiteCountsRejectedProducts(t *testing.T) {
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+	service := &Service{config: &config.Config{}, logger: testLogger(), tiers: tiers}
+
+	scraper := &stubScraper{
+		products: []Product{
+			{Name: "Camiseta", Price: 59.9, ProductURL: "https://loja.com/p/camiseta", Category: "shirt"},
+			{Name: "Calça", Price: 149.9, ProductURL: "https://loja.com/p/calca", Category: "pants"},
+			{Name: "Camiseta", Price: 59.9, ProductURL: "https://loja.com/p/camiseta", Category: "shirt"},
+			{Name: "Camiseta Infantil", Price: 39.9, ProductURL: "https://loja.com/p/infantil", Category: "shirt", Kids: true},
+		},
+		discarded: 2,
+	}
+
+	var result SiteResult
+	run := siteRun{tiers: newPriceTiers(tiers), timeout: time.Minute}
+	products, err := service.scrapeFromSite(context.Background(), scraper, run, &result)
+	if err != nil {
+		t.Fatalf("scrapeFromSite: %v", err)
+	}
+
+	if len(products) != 2 {
+		t.Errorf("got %d products, want the camiseta and the calça once", len(products))
+	}
+	if result.Found != 6 || result.Rejected != 4 {
+		t.Errorf("got %d found and %d rejected, want 6 and 4", result.Found, result.Rejected)
+	}
+	if result.Found != len(products)+result.Rejected {
+		t.Errorf("found %d != %d products + %d rejected", result.Found, len(products), result.Rejected)
+	}
+}
+
+func TestScrapeAllStopsWaitingForSlotsWhenCancelled(t *testing.T) {
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+	service := &Service{
+		config: &config.Config{Concurrency: 1},
+		logger: testLogger(),
+		tiers:  tiers,
+		sites:  []SiteScraper{&stubScraper{}},
+		slots:  make(chan struct{}, 1),
+	}
+	// A scheduled job holds the only slot.
+	service.slots <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	var report *RunReport
+	go func() {
+		report, err = service.ScrapeAll(ctx, runs.TriggerManual)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ScrapeAll kept waiting for a slot after the context was cancelled")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScrapeAll error = %v, want context.Canceled", err)
+	}
+	if site := report.Sites[0]; site.Source != "stub" || !errors.Is(site.Err, context.Canceled) {
+		t.Errorf("site result = %+v, want stub not started", site)
+	}
+}
+
+// detailStub lists fixed products and details them through a site definition.
+type detailStub struct {
+	stubScraper
+	detail *DefinitionScraper
+}
+
+func (s *detailStub) HasDetailPages() bool { return true }
+
+func (s *detailStub) EnrichProduct(ctx context.Context, product *Product) error {
+	return s.detail.EnrichProduct(ctx, product)
+}
+
+func TestScrapeFromSiteTiersCategoryFoundOnDetailPage(t *testing.T) {
+	var definition *SiteDefinition
+	for _, candidate := range loadTestDefinitions(t) {
+		if candidate.Source == "zara" {
+			definition = candidate
+		}
+	}
+	if definition == nil || definition.Detail == nil {
+		t.Fatal("expected the zara definition to describe product pages")
+	}
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+
+	// The listing name does not say what the product is, the product page
+	// describes a camiseta.
+	productURL := definition.BaseURL + "/p/basico-algodao"
+	fetcher := &FixtureFetcher{pages: map[string]string{productURL: filepath.Join(fixturesDir, "zara", "detail.html")}}
+	cfg := &config.Config{DetailEnabled: true, DetailMaxPerRun: 10, DetailTimeout: time.Minute}
+	scraper := &detailStub{
+		stubScraper: stubScraper{products: []Product{
+			{Name: "Básico Algodão", Price: 90, Currency: "BRL", ProductURL: productURL, Category: taxonomy.Unknown},
+		}},
+		detail: NewDefinitionScraper(definition, fetcher, cfg, testLogger()),
+	}
+	service := &Service{config: cfg, logger: testLogger(), tiers: tiers}
+
+	var result SiteResult
+	run := siteRun{tiers: newPriceTiers(tiers), timeout: time.Minute}
+	products, err := service.scrapeFromSite(context.Background(), scraper, run, &result)
+	if err != nil {
+		t.Fatalf("scrapeFromSite: %v", err)
+	}
+
+	if len(products) != 1 || products[0].DetailedAt == nil {
+		t.Fatalf("got %+v, want the detailed product", products)
+	}
+	product := products[0]
+	if product.Category != "shirt" {
+		t.Errorf("Category = %q, want shirt", product.Category)
+	}
+	// 90 is above the shirt economic limit but within the fallback one.
+	if product.TierRule != "shirt" || product.IsEconomic {
+		t.Errorf("got tier rule %q, economic %v, want the shirt rule", product.TierRule, product.IsEconomic)
+	}
+}
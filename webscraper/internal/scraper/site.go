@@ -0,0 +1,474 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"shinewardrobe-scraper/internal/brand"
+	"shinewardrobe-scraper/internal/climate"
+	"shinewardrobe-scraper/internal/config"
+	"shinewardrobe-scraper/internal/pricing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/sirupsen/logrus"
+)
+
+// DefinitionScraper is a SiteScraper driven entirely by a SiteDefinition.
+type DefinitionScraper struct {
+	definition *SiteDefinition
+	fetcher    PageFetcher
+	config     *config.Config
+	climate    climate.Model
+	brands     *brand.Dictionary
+	logger     *logrus.Entry
+}
+
+func NewDefinitionScraper(definition *SiteDefinition, fetcher PageFetcher, cfg *config.Config, logger *logrus.Entry) *DefinitionScraper {
+	return &DefinitionScraper{
+		definition: definition,
+		fetcher:    fetcher,
+		config:     cfg,
+		climate:    climate.ScoringModel{},
+		brands:     brand.Default(),
+		logger:     logger.WithField("scraper", definition.Source),
+	}
+}
+
+func (d *DefinitionScraper) GetName() string {
+	return d.definition.Name
+}
+
+func (d *DefinitionScraper) GetSource() string {
+	return d.definition.Source
+}
+
+func (d *DefinitionScraper) GetCategories() []string {
+	return d.definition.CategoryNames()
+}
+
+func (d *DefinitionScraper) ScrapeProducts(ctx context.Context) ([]Product, error) {
+	products, _, err := d.ScrapeCategories(ctx, nil)
+	return products, err
+}
+
+func (d *DefinitionScraper) ScrapeCategories(ctx context.Context, names []string) ([]Product, ListingStats, error) {
+	categories, err := d.definition.CategoriesNamed(names)
+	if err != nil {
+		return nil, ListingStats{}, err
+	}
+
+	d.logger.Infof("Starting %s scraping...", d.definition.Name)
+
+	var products []Product
+	var stats ListingStats
+	failed := map[string]error{}
+
+	for i, category := range categories {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				failed[category.Name] = ctx.Err()
+				continue
+			case <-time.After(d.definition.Page.CategoryDelay):
+			}
+		}
+
+		d.logger.WithField("category", category.Name).Info("Scraping category...")
+
+		// A category failing after its first page still returns the products
+		// found so far, which are kept.
+		categoryProducts, categoryStats, err := d.scrapeCategory(ctx, category)
+		products = append(products, categoryProducts...)
+		stats.add(categoryStats)
+		if err != nil {
+			d.logger.WithError(err).WithFields(logrus.Fields{
+				"category": category.Name,
+				"products": len(categoryProducts),
+			}).Error("Failed to scrape category")
+			failed[category.Name] = err
+		}
+	}
+
+	if len(failed) == len(categories) && len(products) == 0 {
+		return nil, stats, fmt.Errorf("all categories failed: %v", &PartialScrapeError{Site: d.GetName(), Failed: failed})
+	}
+
+	d.logger.WithField("total_products", len(products)).Infof("%s scraping completed", d.definition.Name)
+	if len(failed) > 0 {
+		return products, stats, &PartialScrapeError{Site: d.GetName(), Failed: failed}
+	}
+	return products, stats, nil
+}
+
+// scrapeCategory returns the products of a category, counting the listed
+// items it discarded and whether the listing was capped.
+func (d *DefinitionScraper) scrapeCategory(ctx context.Context, category CategoryDefinition) ([]Product, ListingStats, error) {
+	pagination := d.definition.Pagination
+	maxPages := 1
+	if pagination.Mode == PaginationNext {
+		maxPages = pagination.MaxPages
+	}
+
+	var products []Product
+	var stats ListingStats
+	capped := false
+	visited := map[string]bool{}
+	seen := map[string]bool{}
+	pageURL := category.URL
+
+	for page := 1; page <= maxPages && pageURL != ""; page++ {
+		key := normalizePageURL(pageURL)
+		if visited[key] {
+			break
+		}
+		visited[key] = true
+
+		// Pages that were not loaded hold products that must not be taken for
+		// missing, so failing after the first page returns the products found
+		// so far with an error, which makes the scrape partial.
+		if page > 1 {
+			select {
+			case <-ctx.Done():
+				return products, stats, fmt.Errorf("stopped before page %d: %w", page, ctx.Err())
+			case <-time.After(pagination.Delay):
+			}
+		}
+
+		htmlContent, err := d.fetchPage(ctx, pageURL, len(products))
+		if err != nil {
+			if page == 1 {
+				return nil, ListingStats{}, fmt.Errorf("failed to load %s page: %w", d.definition.Name, err)
+			}
+			return products, stats, fmt.Errorf("failed to load %s page %d: %w", d.definition.Name, page, err)
+		}
+
+		pageProducts, pageDiscarded, nextURL, err := d.parsePage(htmlContent, pageURL, category.Name)
+		if err != nil {
+			return nil, ListingStats{}, err
+		}
+		stats.Discarded += pageDiscarded
+
+		added := 0
+		for _, product := range pageProducts {
+			if len(products) >= d.config.MaxProducts {
+				capped = true
+				break
+			}
+			if seen[product.ProductURL] {
+				stats.Discarded++
+				continue
+			}
+			seen[product.ProductURL] = true
+			products = append(products, product)
+			added++
+		}
+
+		// Products on the pages left, or still to load by scrolling, were
+		// not looked for.
+		more := nextURL != "" && !visited[normalizePageURL(nextURL)]
+		if len(products) >= d.config.MaxProducts {
+			capped = capped || more || pagination.Mode == PaginationScroll
+			break
+		}
+		if added == 0 {
+			break
+		}
+		if page == maxPages && more {
+			capped = true
+		}
+		pageURL = nextURL
+	}
+
+	if capped {
+		stats.Capped = []string{category.Name}
+	}
+	return products, stats, nil
+}
+
+func (d *DefinitionScraper) fetchPage(ctx context.Context, pageURL string, found int) (string, error) {
+	if d.definition.Pagination.Mode != PaginationScroll {
+		return d.fetcher.FetchPage(ctx, pageURL, d.definition.Page)
+	}
+
+	return d.fetcher.FetchScrolled(ctx, pageURL, d.definition.Page, ScrollOptions{
+		ItemSelector: strings.Join(d.definition.Selectors.Items, ", "),
+		Target:       d.config.MaxProducts - found,
+		MaxScrolls:   d.definition.Pagination.MaxPages,
+		Pause:        d.definition.Pagination.Delay,
+	})
+}
+
+// parsePage extracts the products of a listing page, counts the items
+// discarded for lacking a name or price and, in "next" pagination mode,
+// returns the absolute URL of the following page.
+func (d *DefinitionScraper) parsePage(htmlContent, pageURL, categoryName string) ([]Product, int, string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	structured := extractStructuredData(doc)
+	structuredByKey := map[string]int{}
+	for i, data := range structured {
+		if data.URL == "" {
+			continue
+		}
+		key := productKey(resolveURL(d.definition.BaseURL, data.URL))
+		if _, exists := structuredByKey[key]; !exists {
+			structuredByKey[key] = i
+		}
+	}
+	matched := map[int]bool{}
+
+	var products []Product
+	discarded := 0
+	appendProduct := func(product Product) {
+		d.completeProduct(&product, listingContext{URL: pageURL, Breadcrumb: []string{categoryName}})
+		if product.Name == "" || product.Price <= 0 {
+			discarded++
+			return
+		}
+		products = append(products, product)
+	}
+
+	for _, selector := range d.definition.Selectors.Items {
+		items := doc.Find(selector)
+		if items.Length() > 0 {
+			items.Each(func(i int, s *goquery.Selection) {
+				product := d.extractProduct(s)
+				if index, exists := structuredByKey[productKey(product.ProductURL)]; exists && product.ProductURL != "" {
+					d.applyStructured(&product, structured[index])
+					matched[index] = true
+				}
+				appendProduct(product)
+			})
+			break
+		}
+	}
+
+	// Structured products without a matching listing item are still products,
+	// for instance when a redesign broke every item selector.
+	for i, data := range structured {
+		if matched[i] || data.URL == "" || structuredByKey[productKey(resolveURL(d.definition.BaseURL, data.URL))] != i {
+			continue
+		}
+		product := Product{Source: d.definition.Source, Available: true}
+		d.applyStructured(&product, data)
+		appendProduct(product)
+	}
+
+	var nextURL string
+	if d.definition.Pagination.Mode == PaginationNext {
+		if href, exists := doc.Find(d.definition.Pagination.NextSelector).First().Attr("href"); exists {
+			nextURL = resolveReference(pageURL, href)
+		}
+	}
+
+	return products, discarded, nextURL, nil
+}
+
+// extractProduct reads the raw fields of a listing item through the selectors
+// of the definition. completeProduct derives the remaining fields.
+func (d *DefinitionScraper) extractProduct(s *goquery.Selection) Product {
+	selectors := d.definition.Selectors
+
+	name := trySelectors(s, selectors.Name)
+	priceText := trySelectors(s, selectors.Price)
+
+	image := s.Find(firstNonEmpty(selectors.Image, "img")).First()
+	var imageURL string
+	for _, attribute := range firstNonEmptyList(selectors.ImageAttributes, []string{"src"}) {
+		if value, _ := image.Attr(attribute); value != "" {
+			imageURL = value
+			break
+		}
+	}
+
+	productURL, _ := s.Find(firstNonEmpty(selectors.Link, "a")).First().Attr("href")
+
+	price := d.parsePrice(priceText)
+
+	product := Product{
+		Name:       strings.TrimSpace(name),
+		Price:      price.Current,
+		Currency:   string(price.Currency),
+		ImageURL:   resolveURL(firstNonEmpty(d.definition.ImageBaseURL, d.definition.BaseURL), imageURL),
+		ProductURL: resolveURL(d.definition.BaseURL, productURL),
+		Source:     d.definition.Source,
+		Available:  true,
+	}
+	product.markSelector(FieldName, product.Name != "")
+	product.markSelector(FieldPrice, product.Price > 0)
+	product.markSelector(FieldImageURL, product.ImageURL != "")
+	product.markSelector(FieldProductURL, product.ProductURL != "")
+
+	product.Seller = trySelectors(s, selectors.Seller)
+	product.markSelector(FieldSeller, product.Seller != "")
+
+	originalPrice := price.Original
+	if originalPriceText := trySelectors(s, selectors.OriginalPrice); originalPriceText != "" {
+		originalPrice = d.parsePrice(originalPriceText).Current
+	}
+	if originalPrice > product.Price {
+		product.OriginalPrice = &originalPrice
+		product.markSelector(FieldOriginalPrice, true)
+	}
+
+	product.Installments = price.Installments
+	if installmentsText := trySelectors(s, selectors.Installments); installmentsText != "" {
+		if installments := d.parsePrice(installmentsText).Installments; installments != nil {
+			product.Installments = installments
+		}
+	}
+	product.markSelector(FieldInstallments, product.Installments != nil)
+
+	cashPrice := price.Cash
+	if cashPriceText := trySelectors(s, selectors.CashPrice); cashPriceText != "" {
+		parsed := d.parsePrice(cashPriceText)
+		cashPrice = parsed.Cash
+		if cashPrice == 0 {
+			cashPrice = parsed.Current
+		}
+	}
+	if cashPrice > 0 && cashPrice < product.Price {
+		product.CashPrice = &cashPrice
+		product.markSelector(FieldCashPrice, true)
+	}
+
+	return product
+}
+
+// parsePrice returns the zero Price when text cannot be parsed, which drops
+// the listing item unless structured data has its price.
+func (d *DefinitionScraper) parsePrice(text string) pricing.Price {
+	if text == "" {
+		return pricing.Price{}
+	}
+	price, err := pricing.ParseCurrency(text, pricing.Currency(d.definition.SiteCurrency()))
+	if err != nil {
+		d.logger.WithError(err).Debug("Failed to parse price")
+		return pricing.Price{}
+	}
+	return price
+}
+
+func (d *DefinitionScraper) applyStructured(product *Product, data structuredProduct) {
+	applyStructured(product, data, d.definition.BaseURL, firstNonEmpty(d.definition.ImageBaseURL, d.definition.BaseURL))
+}
+
+// completeProduct fills the fields derived from the extracted ones: brand,
+// categorization, gender, weather and the listing defaults.
+func (d *DefinitionScraper) completeProduct(product *Product, listing listingContext) {
+	product.Brand = d.resolveBrand(product)
+	if d.definition.Marketplace {
+		product.Seller = brand.Seller(product.Seller)
+	} else {
+		product.Seller = ""
+		delete(product.FieldSources, FieldSeller)
+	}
+	if product.Currency == "" {
+		product.Currency = d.definition.SiteCurrency()
+	}
+	if product.OriginalPrice != nil && *product.OriginalPrice <= product.Price {
+		product.OriginalPrice = nil
+		delete(product.FieldSources, FieldOriginalPrice)
+	}
+	if product.CashPrice != nil && *product.CashPrice >= product.Price {
+		product.CashPrice = nil
+		delete(product.FieldSources, FieldCashPrice)
+	}
+
+	classifyProduct(product, listing)
+	detectGender(product, listing)
+	assessClimate(d.climate, product)
+	if product.Sizes == nil {
+		product.Sizes = d.definition.Defaults.Sizes
+	}
+	if len(product.Colors) == 0 {
+		product.Colors = d.definition.Defaults.Colors
+	}
+}
+
+// resolveBrand prefers the brand the store gives, then one named in the
+// product name, then the store's own brand. A marketplace's own name is not a
+// brand, so its products may have none.
+func (d *DefinitionScraper) resolveBrand(product *Product) string {
+	name := d.brands.Canonical(product.Brand)
+	if d.definition.Marketplace && brand.Key(name) == brand.Key(d.definition.Name) {
+		name = ""
+	}
+	if name != "" {
+		return name
+	}
+	if found, ok := d.brands.Find(product.Name); ok {
+		return found
+	}
+	if d.definition.Marketplace {
+		return ""
+	}
+	return d.definition.Brand.Default
+}
+
+func trySelectors(s *goquery.Selection, selectors []string) string {
+	for _, selector := range selectors {
+		if text := strings.TrimSpace(s.Find(selector).First().Text()); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func resolveURL(baseURL, value string) string {
+	if value == "" || strings.HasPrefix(value, "http") {
+		return value
+	}
+	if strings.HasPrefix(value, "//") {
+		return "https:" + value
+	}
+	return baseURL + value
+}
+
+func resolveReference(pageURL, href string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	reference, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || strings.HasPrefix(reference.Scheme, "javascript") {
+		return ""
+	}
+	return base.ResolveReference(reference).String()
+}
+
+// normalizePageURL drops fragments and orders query parameters so the same
+// page is not visited twice through differently written links.
+func normalizePageURL(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	parsed.Fragment = ""
+	parsed.RawQuery = parsed.Query().Encode()
+	return parsed.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func firstNonEmptyList(lists ...[]string) []string {
+	for _, list := range lists {
+		if len(list) > 0 {
+			return list
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shinewardrobe-scraper/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+const (
+	fixturesDir = "testdata/fixtures"
+	goldenDir   = "testdata/golden"
+)
+
+func testLogger() *logrus.Entry {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logrus.NewEntry(logger)
+}
+
+func loadTestDefinitions(t *testing.T) []*SiteDefinition {
+	t.Helper()
+
+	definitions, err := LoadDefinitions("")
+	if err != nil {
+		t.Fatalf("LoadDefinitions: %v", err)
+	}
+	for _, definition := range definitions {
+		definition.Page.CategoryDelay = 0
+	}
+	return definitions
+}
+
+func TestScrapersAgainstFixtures(t *testing.T) {
+	cfg := &config.Config{MaxProducts: 50}
+
+	for _, definition := range loadTestDefinitions(t) {
+		definition := definition
+		t.Run(definition.Source, func(t *testing.T) {
+			fetcher := NewFixtureFetcher(fixturesDir, definition)
+			scraper := NewDefinitionScraper(definition, fetcher, cfg, testLogger())
+
+			products, err := scraper.ScrapeProducts(context.Background())
+			if err != nil {
+				t.Fatalf("ScrapeProducts: %v", err)
+			}
+			if len(products) == 0 {
+				t.Fatal("expected products from fixtures, got none")
+			}
+
+			assertGolden(t, filepath.Join(goldenDir, definition.Source+".json"), products)
+		})
+	}
+}
+
+func TestScraperRespectsMaxProducts(t *testing.T) {
+	cfg := &config.Config{MaxProducts: 1}
+
+	for _, definition := range loadTestDefinitions(t) {
+		definition := definition
+		t.Run(definition.Source, func(t *testing.T) {
+			fetcher := NewFixtureFetcher(fixturesDir, definition)
+			scraper := NewDefinitionScraper(definition, fetcher, cfg, testLogger())
+			full := NewDefinitionScraper(definition, fetcher, &config.Config{MaxProducts: 50}, testLogger())
+
+			for _, category := range definition.Categories {
+				listed, _, err := full.scrapeCategory(context.Background(), category)
+				if err != nil {
+					t.Fatalf("scrapeCategory(%s): %v", category.Name, err)
+				}
+				products, stats, err := scraper.scrapeCategory(context.Background(), category)
+				if err != nil {
+					t.Fatalf("scrapeCategory(%s): %v", category.Name, err)
+				}
+				if len(products) > 1 {
+					t.Errorf("scrapeCategory(%s) returned %d products, want at most 1", category.Name, len(products))
+				}
+				// Products past the first were not looked for.
+				if capped := len(stats.Capped) == 1 && stats.Capped[0] == category.Name; capped != (len(listed) > 1) {
+					t.Errorf("scrapeCategory(%s) of %d products reported %v capped", category.Name, len(listed), stats.Capped)
+				}
+			}
+		})
+	}
+}
+
+func TestScraperReportsMissingFixturesAsPartial(t *testing.T) {
+	definition := loadTestDefinitions(t)[0]
+	fetcher := NewFixtureFetcher(fixturesDir, definition)
+	delete(fetcher.pages, definition.Categories[0].URL)
+
+	scraper := NewDefinitionScraper(definition, fetcher, &config.Config{MaxProducts: 50}, testLogger())
+
+	products, err := scraper.ScrapeProducts(context.Background())
+	partial, ok := err.(*PartialScrapeError)
+	if !ok {
+		t.Fatalf("expected *PartialScrapeError, got %v", err)
+	}
+	if _, failed := partial.Failed[definition.Categories[0].Name]; !failed {
+		t.Errorf("expected %s to be reported as failed, got %v", definition.Categories[0].Name, partial.Failed)
+	}
+	if len(products) == 0 {
+		t.Error("expected products from the remaining categories")
+	}
+}
+
+func assertGolden(t *testing.T, path string, products []Product) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(products, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal products: %v", err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run go test ./internal/scraper -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("products differ from %s (run go test ./internal/scraper -update to accept)\n got: %s", path, got)
+	}
+}
+
+func TestScrapeCategoriesLimitsCategories(t *testing.T) {
+	definition := loadTestDefinitions(t)[0]
+	scraper := NewDefinitionScraper(definition, NewFixtureFetcher(fixturesDir, definition), &config.Config{MaxProducts: 50}, testLogger())
+
+	category := definition.Categories[1].Name
+	products, stats, err := scraper.ScrapeCategories(context.Background(), []string{category})
+	if err != nil {
+		t.Fatalf("ScrapeCategories: %v", err)
+	}
+	if len(stats.Capped) > 0 {
+		t.Errorf("got %v capped, want the category listed in full", stats.Capped)
+	}
+	all, _ := scraper.ScrapeProducts(context.Background())
+	if len(products) == 0 || len(products) >= len(all) {
+		t.Errorf("got %d products from %s, want some of the %d of the site", len(products), category, len(all))
+	}
+
+	if _, _, err := scraper.ScrapeCategories(context.Background(), []string{"meias"}); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
+
+func TestScraperReportsFailedNextPageAsPartial(t *testing.T) {
+	var definition *SiteDefinition
+	for _, candidate := range loadTestDefinitions(t) {
+		if candidate.Pagination.Mode == PaginationNext {
+			definition = candidate
+			break
+		}
+	}
+	if definition == nil {
+		t.Skip("no site definition uses next page pagination")
+	}
+	definition.Pagination.Delay = 0
+	category := definition.Categories[0]
+
+	// The first page links to a second one that has no fixture, so loading
+	// it fails.
+	fetcher := NewFixtureFetcher(fixturesDir, definition)
+	html, err := os.ReadFile(fetcher.pages[category.URL])
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := strings.Replace(string(html), "</body>", `<a rel="next" class="pagination__next" href="?page=2">2</a></body>`, 1)
+	path := filepath.Join(t.TempDir(), "page-1.html")
+	if err := os.WriteFile(path, []byte(linked), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fetcher.pages[category.URL] = path
+
+	scraper := NewDefinitionScraper(definition, fetcher, &config.Config{MaxProducts: 500}, testLogger())
+	products, _, err := scraper.ScrapeCategories(context.Background(), []string{category.Name})
+	partial, ok := err.(*PartialScrapeError)
+	if !ok {
+		t.Fatalf("expected *PartialScrapeError, got %v", err)
+	}
+	if _, failed := partial.Failed[category.Name]; !failed {
+		t.Errorf("expected %s to be reported as failed, got %v", category.Name, partial.Failed)
+	}
+	if len(products) == 0 {
+		t.Error("expected the products of the first page")
+	}
+}
+
+func TestScraperReportsPaginationLimitAsCapped(t *testing.T) {
+	var definition *SiteDefinition
+	for _, candidate := range loadTestDefinitions(t) {
+		if candidate.Pagination.Mode == PaginationNext {
+			definition = candidate
+			break
+		}
+	}
+	if definition == nil {
+		t.Skip("no site definition uses next page pagination")
+	}
+	definition.Pagination.MaxPages = 1
+	category := definition.Categories[0]
+
+	// The first page links to a second one, which max_pages leaves unread.
+	fetcher := NewFixtureFetcher(fixturesDir, definition)
+	html, err := os.ReadFile(fetcher.pages[category.URL])
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := strings.Replace(string(html), "</body>", `<a rel="next" class="pagination__next" href="?page=2">2</a></body>`, 1)
+	path := filepath.Join(t.TempDir(), "page-1.html")
+	if err := os.WriteFile(path, []byte(linked), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fetcher.pages[category.URL] = path
+
+	scraper := NewDefinitionScraper(definition, fetcher, &config.Config{MaxProducts: 500}, testLogger())
+	products, stats, err := scraper.ScrapeCategories(context.Background(), []string{category.Name})
+	if err != nil {
+		t.Fatalf("ScrapeCategories: %v", err)
+	}
+	if len(products) == 0 {
+		t.Error("expected the products of the first page")
+	}
+	if len(stats.Capped) != 1 || stats.Capped[0] != category.Name {
+		t.Errorf("got %v capped, want %s", stats.Capped, category.Name)
+	}
+}
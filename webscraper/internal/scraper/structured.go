@@ -0,0 +1,547 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Strategy names the extraction method that produced a product field.
+type Strategy string
+
+const (
+	StrategyJSONLD        Strategy = "json-ld"
+	StrategyEmbeddedState Strategy = "embedded-state"
+	StrategySelector      Strategy = "selector"
+)
+
+// Field names used as keys of Product.FieldSources.
+const (
+	FieldName          = "name"
+	FieldBrand         = "brand"
+	FieldSeller        = "seller"
+	FieldPrice         = "price"
+	FieldOriginalPrice = "original_price"
+	FieldInstallments  = "installments"
+	FieldCashPrice     = "cash_price"
+	FieldCurrency      = "currency"
+	FieldImageURL      = "image_url"
+	FieldProductURL    = "product_url"
+	FieldDescription   = "description"
+	FieldMaterial      = "material"
+	FieldSizes         = "sizes"
+	FieldColors        = "colors"
+	FieldImages        = "images"
+)
+
+// structuredProduct is a product read from schema.org JSON-LD or from the
+// JSON state a storefront embeds for client-side rendering.
+type structuredProduct struct {
+	Name          string
+	Brand         string
+	Seller        string
+	Description   string
+	URL           string
+	Color         string
+	Material      string
+	Currency      string
+	Price         float64
+	OriginalPrice float64
+	Images        []string
+	strategy      Strategy
+}
+
+var (
+	statePriceKeys     = []string{"sellingPrice", "salePrice", "bestPrice", "price", "Price", "lowPrice"}
+	stateListPriceKeys = []string{"listPrice", "ListPrice", "originalPrice", "oldPrice"}
+	stateNameKeys      = []string{"productName", "name", "title"}
+	stateImageKeys     = []string{"images", "image", "imageUrl", "imageURL", "thumbnail"}
+)
+
+// extractStructuredData returns every product described by JSON-LD blocks and
+// by __NEXT_DATA__ or __STATE__ payloads in doc, in document order.
+func extractStructuredData(doc *goquery.Document) []structuredProduct {
+	var products []structuredProduct
+
+	doc.Find("script[type='application/ld+json']").Each(func(i int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s.Text())), &data); err != nil {
+			return
+		}
+		products = append(products, schemaProducts(data, StrategyJSONLD)...)
+	})
+
+	for _, state := range embeddedStates(doc) {
+		found := schemaProducts(state, StrategyEmbeddedState)
+		if len(found) == 0 {
+			found = stateProducts(state)
+		}
+		products = append(products, found...)
+	}
+
+	return products
+}
+
+func embeddedStates(doc *goquery.Document) []interface{} {
+	var payloads []string
+
+	doc.Find("script#__NEXT_DATA__, template[data-varname='__STATE__'] script").Each(func(i int, s *goquery.Selection) {
+		payloads = append(payloads, s.Text())
+	})
+	doc.Find("script").Each(func(i int, s *goquery.Selection) {
+		text := strings.TrimSpace(s.Text())
+		if !strings.HasPrefix(text, "window.__STATE__") {
+			return
+		}
+		if start := strings.Index(text, "="); start >= 0 {
+			payloads = append(payloads, strings.TrimSuffix(strings.TrimSpace(text[start+1:]), ";"))
+		}
+	})
+
+	var states []interface{}
+	for _, payload := range payloads {
+		var state interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(payload)), &state); err == nil {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// schemaProducts walks data for schema.org Product nodes, including the ones
+// nested in ItemList elements and @graph arrays.
+func schemaProducts(data interface{}, strategy Strategy) []structuredProduct {
+	var products []structuredProduct
+
+	switch value := data.(type) {
+	case []interface{}:
+		for _, item := range value {
+			products = append(products, schemaProducts(item, strategy)...)
+		}
+	case map[string]interface{}:
+		if !hasSchemaType(value, "Product") {
+			for _, key := range sortedKeys(value) {
+				products = append(products, schemaProducts(value[key], strategy)...)
+			}
+			return products
+		}
+
+		product := structuredProduct{
+			Name:        stringValue(value["name"]),
+			Brand:       nameValue(value["brand"]),
+			Description: stringValue(value["description"]),
+			URL:         stringValue(value["url"]),
+			Color:       stringValue(value["color"]),
+			Material:    nameValue(value["material"]),
+			Images:      imageValues(value["image"], nil),
+			strategy:    strategy,
+		}
+		product.Price, product.OriginalPrice = offerPrices(value["offers"])
+		product.Currency = offerCurrency(value["offers"])
+		product.Seller = offerSeller(value["offers"])
+		if product.URL == "" {
+			product.URL = offerURL(value["offers"])
+		}
+		products = append(products, product)
+	}
+
+	return products
+}
+
+func hasSchemaType(node map[string]interface{}, schemaType string) bool {
+	types, ok := node["@type"].([]interface{})
+	if !ok {
+		types = []interface{}{node["@type"]}
+	}
+	for _, item := range types {
+		name := stringValue(item)
+		if name == schemaType || name == "https://schema.org/"+schemaType || name == "http://schema.org/"+schemaType {
+			return true
+		}
+	}
+	return false
+}
+
+// offerPrices returns the lowest price of an Offer, AggregateOffer or list of
+// offers, and a list price when a price specification marks one.
+func offerPrices(offers interface{}) (float64, float64) {
+	var price, listPrice float64
+
+	switch value := offers.(type) {
+	case []interface{}:
+		for _, offer := range value {
+			offerPrice, offerListPrice := offerPrices(offer)
+			if offerPrice > 0 && (price == 0 || offerPrice < price) {
+				price = offerPrice
+			}
+			if offerListPrice > listPrice {
+				listPrice = offerListPrice
+			}
+		}
+	case map[string]interface{}:
+		if hasSchemaType(value, "AggregateOffer") {
+			price = numberValue(value["lowPrice"])
+			if price == 0 {
+				price, listPrice = offerPrices(value["offers"])
+			}
+		} else {
+			price = numberValue(value["price"])
+		}
+
+		specifications := value["priceSpecification"]
+		if specification, ok := specifications.(map[string]interface{}); ok {
+			specifications = []interface{}{specification}
+		}
+		if list, ok := specifications.([]interface{}); ok {
+			for _, item := range list {
+				specification, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				priceType := stringValue(specification["priceType"])
+				specificationPrice := numberValue(specification["price"])
+				switch {
+				case strings.HasSuffix(priceType, "ListPrice"), strings.HasSuffix(priceType, "StrikethroughPrice"):
+					if specificationPrice > listPrice {
+						listPrice = specificationPrice
+					}
+				case price == 0:
+					price = specificationPrice
+				}
+			}
+		}
+	}
+
+	if listPrice <= price {
+		listPrice = 0
+	}
+	return price, listPrice
+}
+
+func offerCurrency(offers interface{}) string {
+	switch value := offers.(type) {
+	case []interface{}:
+		for _, offer := range value {
+			if currency := offerCurrency(offer); currency != "" {
+				return currency
+			}
+		}
+	case map[string]interface{}:
+		if currency := strings.ToUpper(stringValue(value["priceCurrency"])); currencyPattern.MatchString(currency) {
+			return currency
+		}
+		return offerCurrency(value["offers"])
+	}
+	return ""
+}
+
+func offerSeller(offers interface{}) string {
+	switch value := offers.(type) {
+	case []interface{}:
+		for _, offer := range value {
+			if seller := offerSeller(offer); seller != "" {
+				return seller
+			}
+		}
+	case map[string]interface{}:
+		if seller := nameValue(value["seller"]); seller != "" {
+			return seller
+		}
+		return offerSeller(value["offers"])
+	}
+	return ""
+}
+
+func offerURL(offers interface{}) string {
+	switch value := offers.(type) {
+	case []interface{}:
+		for _, offer := range value {
+			if offerURL := offerURL(offer); offerURL != "" {
+				return offerURL
+			}
+		}
+	case map[string]interface{}:
+		return stringValue(value["url"])
+	}
+	return ""
+}
+
+// stateProducts finds product-like objects in embedded state that does not
+// use schema.org. Normalized caches such as the VTEX __STATE__ reference
+// nested objects by id, so values are resolved against the root object.
+func stateProducts(state interface{}) []structuredProduct {
+	root, _ := state.(map[string]interface{})
+	resolve := func(value interface{}) interface{} {
+		reference, ok := value.(map[string]interface{})
+		if !ok || root == nil {
+			return value
+		}
+		if id, ok := reference["__ref"].(string); ok {
+			return root[id]
+		}
+		if id, ok := reference["id"].(string); ok && reference["type"] == "id" {
+			return root[id]
+		}
+		return value
+	}
+
+	var products []structuredProduct
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch node := value.(type) {
+		case []interface{}:
+			for _, item := range node {
+				walk(item)
+			}
+		case map[string]interface{}:
+			if product, ok := stateProduct(node, resolve); ok {
+				products = append(products, product)
+				return
+			}
+			for _, key := range sortedKeys(node) {
+				walk(node[key])
+			}
+		}
+	}
+	walk(state)
+
+	return products
+}
+
+func stateProduct(node map[string]interface{}, resolve func(interface{}) interface{}) (structuredProduct, bool) {
+	var name string
+	for _, key := range stateNameKeys {
+		if name = stringValue(node[key]); name != "" {
+			break
+		}
+	}
+
+	productURL := firstNonEmpty(stringValue(node["link"]), stringValue(node["url"]), stringValue(node["href"]))
+	if linkText := stringValue(node["linkText"]); productURL == "" && linkText != "" {
+		// VTEX product pages live at /<linkText>/p.
+		productURL = "/" + linkText + "/p"
+	}
+
+	price := statePrice(node, statePriceKeys, resolve)
+	if name == "" || productURL == "" || price <= 0 {
+		return structuredProduct{}, false
+	}
+
+	product := structuredProduct{
+		Name:        name,
+		Brand:       nameValue(resolve(node["brand"])),
+		Seller:      firstNonEmpty(stringValue(node["sellerName"]), nameValue(resolve(node["seller"]))),
+		Description: stringValue(node["description"]),
+		URL:         productURL,
+		Price:       price,
+		strategy:    StrategyEmbeddedState,
+	}
+	if listPrice := statePrice(node, stateListPriceKeys, resolve); listPrice > price {
+		product.OriginalPrice = listPrice
+	}
+	for _, key := range stateImageKeys {
+		if images := imageValues(node[key], resolve); len(images) > 0 {
+			product.Images = images
+			break
+		}
+	}
+	if len(product.Images) == 0 {
+		if items, ok := resolve(node["items"]).([]interface{}); ok && len(items) > 0 {
+			if item, ok := resolve(items[0]).(map[string]interface{}); ok {
+				product.Images = imageValues(item["images"], resolve)
+			}
+		}
+	}
+
+	return product, true
+}
+
+func statePrice(node map[string]interface{}, keys []string, resolve func(interface{}) interface{}) float64 {
+	if priceRange, ok := resolve(node["priceRange"]).(map[string]interface{}); ok {
+		if price := statePrice(priceRange, keys, resolve); price > 0 {
+			return price
+		}
+	}
+
+	for _, key := range keys {
+		value := resolve(node[key])
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = resolve(firstPresent(nested, "lowPrice", "value", "amount"))
+		}
+		if price := numberValue(value); price > 0 {
+			return price
+		}
+	}
+	return 0
+}
+
+func sortedKeys(node map[string]interface{}) []string {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstPresent(node map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := node[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+func stringValue(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+// nameValue reads values that schema.org allows as either text or an object
+// with a name, such as brand and material.
+func nameValue(value interface{}) string {
+	switch node := value.(type) {
+	case string:
+		return strings.TrimSpace(node)
+	case map[string]interface{}:
+		return stringValue(node["name"])
+	case []interface{}:
+		if len(node) > 0 {
+			return nameValue(node[0])
+		}
+	}
+	return ""
+}
+
+// numberValue reads prices written as JSON numbers or as decimal strings such
+// as "129.90". Structured data always uses a dot as the decimal separator.
+func numberValue(value interface{}) float64 {
+	switch number := value.(type) {
+	case float64:
+		return number
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
+
+func imageValues(value interface{}, resolve func(interface{}) interface{}) []string {
+	if resolve != nil {
+		value = resolve(value)
+	}
+
+	switch node := value.(type) {
+	case string:
+		if image := strings.TrimSpace(node); image != "" {
+			return []string{image}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"url", "contentUrl", "imageUrl", "src"} {
+			if image := stringValue(node[key]); image != "" {
+				return []string{image}
+			}
+		}
+	case []interface{}:
+		var images []string
+		for _, item := range node {
+			images = append(images, imageValues(item, resolve)...)
+		}
+		return images
+	}
+	return nil
+}
+
+// productKey identifies a product page independently of scheme, query string
+// and trailing slash so structured data can be matched with listing links.
+func productKey(productURL string) string {
+	parsed, err := url.Parse(productURL)
+	if err != nil {
+		return productURL
+	}
+	return strings.ToLower(parsed.Host + strings.TrimSuffix(parsed.Path, "/"))
+}
+
+// applyStructured copies the fields found in data over product, recording the
+// strategy for each one. Fields data does not have are left untouched.
+func applyStructured(product *Product, data structuredProduct, baseURL, imageBaseURL string) {
+	set := func(field string) {
+		product.recordSource(field, data.strategy)
+	}
+
+	if data.Name != "" {
+		product.Name = data.Name
+		set(FieldName)
+	}
+	if data.Brand != "" {
+		product.Brand = data.Brand
+		set(FieldBrand)
+	}
+	if data.Seller != "" {
+		product.Seller = data.Seller
+		set(FieldSeller)
+	}
+	if data.Price > 0 {
+		product.Price = data.Price
+		set(FieldPrice)
+		if data.Currency != "" {
+			product.Currency = data.Currency
+			set(FieldCurrency)
+		}
+		if data.OriginalPrice > data.Price {
+			originalPrice := data.OriginalPrice
+			product.OriginalPrice = &originalPrice
+			set(FieldOriginalPrice)
+		}
+	}
+	if data.URL != "" && product.ProductURL == "" {
+		product.ProductURL = resolveURL(baseURL, data.URL)
+		set(FieldProductURL)
+	}
+	if data.Description != "" {
+		product.Description = strings.Join(strings.Fields(data.Description), " ")
+		set(FieldDescription)
+	}
+	if data.Material != "" {
+		product.Material = data.Material
+		set(FieldMaterial)
+	}
+	if data.Color != "" {
+		product.Colors = []string{data.Color}
+		set(FieldColors)
+	}
+	if len(data.Images) > 0 {
+		images := make([]string, len(data.Images))
+		for i, image := range data.Images {
+			images[i] = resolveURL(imageBaseURL, image)
+		}
+		product.ImageURL = images[0]
+		set(FieldImageURL)
+		if len(images) > 1 {
+			product.Images = images
+			set(FieldImages)
+		}
+	}
+}
+
+func (p *Product) recordSource(field string, strategy Strategy) {
+	if p.FieldSources == nil {
+		p.FieldSources = map[string]Strategy{}
+	}
+	p.FieldSources[field] = strategy
+}
+
+func (p *Product) markSelector(field string, found bool) {
+	if found {
+		p.recordSource(field, StrategySelector)
+	}
+}
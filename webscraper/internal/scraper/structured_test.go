@@ -0,0 +1,199 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"shinewardrobe-scraper/internal/config"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const jsonLDListing = `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "ItemList", "itemListElement": [
+  {"@type": "ListItem", "position": 1, "item": {
+    "@type": "Product", "name": "Camiseta Básica Algodão", "url": "https://www.lojasrenner.com.br/p/camiseta-basica/-/A-1",
+    "image": ["/img/basica-1.jpg", "/img/basica-2.jpg"], "brand": {"@type": "Brand", "name": "Blue Steel"},
+    "offers": {"@type": "Offer", "price": "49.90", "priceCurrency": "BRL",
+      "priceSpecification": {"@type": "UnitPriceSpecification", "priceType": "https://schema.org/ListPrice", "price": 69.9}}}},
+  {"@type": "ListItem", "position": 2, "item": {
+    "@type": "Product", "name": "Vestido Midi Floral", "url": "/p/vestido-midi/-/A-2",
+    "offers": {"@type": "AggregateOffer", "lowPrice": 179.9, "highPrice": 199.9}}}
+]}
+</script>
+</head><body>
+<div class="showcase-item">
+  <a href="/p/camiseta-basica/-/A-1?sku=10"><img data-src="/img/selector.jpg"></a>
+  <p class="showcase-item-name">Camiseta</p>
+</div>
+</body></html>`
+
+const nextDataListing = `<html><body>
+<script id="__NEXT_DATA__" type="application/json">
+{"props": {"pageProps": {"products": [
+  {"id": 1, "name": "Calça Jeans Reta", "url": "/p/calca-jeans-reta", "price": {"value": 159.9}, "listPrice": 199.9, "images": [{"url": "/img/reta.jpg"}]}
+]}}}
+</script>
+</body></html>`
+
+const vtexStateListing = `<html><body>
+<template data-type="json" data-varname="__STATE__"><script>
+{"Product:sp-1": {"productName": "Camisa Linho Manga Longa", "linkText": "camisa-linho", "brand": "Ateen",
+   "priceRange": {"type": "id", "id": "$Product:sp-1.priceRange"},
+   "items": [{"type": "id", "id": "Product:sp-1.items.0"}]},
+ "$Product:sp-1.priceRange": {"sellingPrice": {"type": "id", "id": "$Product:sp-1.priceRange.sellingPrice"},
+   "listPrice": {"type": "id", "id": "$Product:sp-1.priceRange.listPrice"}},
+ "$Product:sp-1.priceRange.sellingPrice": {"lowPrice": 229.9, "highPrice": 229.9},
+ "$Product:sp-1.priceRange.listPrice": {"lowPrice": 299.9, "highPrice": 299.9},
+ "Product:sp-1.items.0": {"images": [{"type": "id", "id": "Image:1"}]},
+ "Image:1": {"imageUrl": "https://ateen.vteximg.com.br/arquivos/linho.jpg"}}
+</script></template>
+</body></html>`
+
+func rennerTestScraper(t *testing.T) *DefinitionScraper {
+	t.Helper()
+
+	for _, definition := range loadTestDefinitions(t) {
+		if definition.Source == "renner" {
+			return NewDefinitionScraper(definition, nil, &config.Config{MaxProducts: 50}, testLogger())
+		}
+	}
+	t.Fatal("renner definition not found")
+	return nil
+}
+
+func TestParsePagePrefersStructuredData(t *testing.T) {
+	scraper := rennerTestScraper(t)
+
+	products, _, _, err := scraper.parsePage(jsonLDListing, "https://www.lojasrenner.com.br/c/feminino", "camisetas-feminino")
+	if err != nil {
+		t.Fatalf("parsePage: %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("got %d products, want 2: %+v", len(products), products)
+	}
+
+	merged := products[0]
+	if merged.Name != "Camiseta Básica Algodão" || merged.Price != 49.9 || merged.Brand != "Blue Steel" {
+		t.Errorf("merged product = %+v", merged)
+	}
+	if merged.OriginalPrice == nil || *merged.OriginalPrice != 69.9 {
+		t.Errorf("OriginalPrice = %v, want 69.9", merged.OriginalPrice)
+	}
+	if merged.ProductURL != "https://www.lojasrenner.com.br/p/camiseta-basica/-/A-1?sku=10" {
+		t.Errorf("ProductURL = %q, want the listing link", merged.ProductURL)
+	}
+	wantSources := map[string]Strategy{
+		FieldName:       StrategyJSONLD,
+		FieldPrice:      StrategyJSONLD,
+		FieldImageURL:   StrategyJSONLD,
+		FieldProductURL: StrategySelector,
+	}
+	for field, want := range wantSources {
+		if got := merged.FieldSources[field]; got != want {
+			t.Errorf("FieldSources[%s] = %q, want %q", field, got, want)
+		}
+	}
+
+	structuredOnly := products[1]
+	if structuredOnly.ProductURL != "https://www.lojasrenner.com.br/p/vestido-midi/-/A-2" || structuredOnly.Price != 179.9 {
+		t.Errorf("structured-only product = %+v", structuredOnly)
+	}
+	if structuredOnly.FieldSources[FieldProductURL] != StrategyJSONLD {
+		t.Errorf("FieldSources[product_url] = %q, want %q", structuredOnly.FieldSources[FieldProductURL], StrategyJSONLD)
+	}
+}
+
+func TestExtractStructuredDataFromEmbeddedState(t *testing.T) {
+	tests := []struct {
+		name          string
+		html          string
+		want          structuredProduct
+		wantStrategy  Strategy
+		wantImageURLs []string
+	}{
+		{
+			name:          "next data",
+			html:          nextDataListing,
+			want:          structuredProduct{Name: "Calça Jeans Reta", URL: "/p/calca-jeans-reta", Price: 159.9, OriginalPrice: 199.9},
+			wantStrategy:  StrategyEmbeddedState,
+			wantImageURLs: []string{"/img/reta.jpg"},
+		},
+		{
+			name:          "vtex state",
+			html:          vtexStateListing,
+			want:          structuredProduct{Name: "Camisa Linho Manga Longa", Brand: "Ateen", URL: "/camisa-linho/p", Price: 229.9, OriginalPrice: 299.9},
+			wantStrategy:  StrategyEmbeddedState,
+			wantImageURLs: []string{"https://ateen.vteximg.com.br/arquivos/linho.jpg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			products := extractStructuredData(doc)
+			if len(products) != 1 {
+				t.Fatalf("got %d products, want 1: %+v", len(products), products)
+			}
+
+			got := products[0]
+			if got.Name != tt.want.Name || got.Brand != tt.want.Brand || got.URL != tt.want.URL ||
+				got.Price != tt.want.Price || got.OriginalPrice != tt.want.OriginalPrice {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.strategy != tt.wantStrategy {
+				t.Errorf("strategy = %q, want %q", got.strategy, tt.wantStrategy)
+			}
+			if strings.Join(got.Images, ",") != strings.Join(tt.wantImageURLs, ",") {
+				t.Errorf("Images = %v, want %v", got.Images, tt.wantImageURLs)
+			}
+		})
+	}
+}
+
+const marketplaceListing = `<html><head>
+<script type="application/ld+json">
+[{"@context": "https://schema.org", "@type": "Product", "name": "Tênis Nike Revolution 7", "url": "/produto/1",
+  "brand": "Americanas", "offers": {"@type": "Offer", "price": 399.9, "seller": {"@type": "Organization", "name": "Loja Oficial Nike"}}},
+ {"@context": "https://schema.org", "@type": "Product", "name": "Calça Jeans 511 Slim", "url": "/produto/2",
+  "brand": {"@type": "Brand", "name": "LEVIS"}, "offers": {"@type": "Offer", "price": 299.9}},
+ {"@context": "https://schema.org", "@type": "Product", "name": "Camiseta Básica Algodão", "url": "/produto/3",
+  "offers": {"@type": "Offer", "price": 39.9}}]
+</script>
+</head><body></body></html>`
+
+func TestParsePageSeparatesMarketplaceSeller(t *testing.T) {
+	var scraper *DefinitionScraper
+	for _, definition := range loadTestDefinitions(t) {
+		if definition.Source == "americanas" {
+			scraper = NewDefinitionScraper(definition, nil, &config.Config{MaxProducts: 50}, testLogger())
+		}
+	}
+	if scraper == nil {
+		t.Fatal("americanas definition not found")
+	}
+
+	products, _, _, err := scraper.parsePage(marketplaceListing, "https://www.americanas.com.br/categoria/moda", "moda")
+	if err != nil {
+		t.Fatalf("parsePage: %v", err)
+	}
+	if len(products) != 3 {
+		t.Fatalf("got %d products, want 3: %+v", len(products), products)
+	}
+
+	want := []struct{ brand, seller string }{
+		{"Nike", "Loja Oficial Nike"},
+		{"Levi's", ""},
+		{"", ""},
+	}
+	for i, product := range products {
+		if product.Brand != want[i].brand || product.Seller != want[i].seller {
+			t.Errorf("%s: brand %q and seller %q, want %q and %q", product.Name, product.Brand, product.Seller, want[i].brand, want[i].seller)
+		}
+	}
+}
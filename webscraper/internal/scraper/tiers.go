@@ -0,0 +1,233 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed tiers.yaml
+var embeddedTiers []byte
+
+const (
+	TierModeRules      = "rules"
+	TierModePercentile = "percentile"
+)
+
+// TierConfig decides which products are economic or luxury.
+type TierConfig struct {
+	Mode       string               `yaml:"mode"`
+	Percentile PercentileDefinition `yaml:"percentile"`
+	Rules      []TierRule           `yaml:"rules"`
+	Fallback   TierRule             `yaml:"fallback"`
+}
+
+// PercentileDefinition sets the price percentiles, between 0 and 1, below and
+// above which products of a category are economic and luxury.
+type PercentileDefinition struct {
+	Economic   float64 `yaml:"economic"`
+	Luxury     float64 `yaml:"luxury"`
+	MinSamples int     `yaml:"min_samples"`
+}
+
+// TierRule holds BRL thresholds for the products it matches. Empty match
+// fields match anything and zero thresholds are not applied.
+type TierRule struct {
+	Name        string  `yaml:"name"`
+	Category    string  `yaml:"category"`
+	Subcategory string  `yaml:"subcategory"`
+	Gender      string  `yaml:"gender"`
+	Source      string  `yaml:"source"`
+	EconomicMax float64 `yaml:"economic_max"`
+	LuxuryMin   float64 `yaml:"luxury_min"`
+}
+
+// LoadTierConfig reads the tier rules in path, or the bundled ones when path
+// is empty.
+func LoadTierConfig(path string) (*TierConfig, error) {
+	if path == "" {
+		return ParseTierConfig("tiers.yaml", embeddedTiers)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tier rules: %w", err)
+	}
+	return ParseTierConfig(path, data)
+}
+
+func ParseTierConfig(name string, data []byte) (*TierConfig, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	tiers := &TierConfig{Mode: TierModeRules}
+	if err := decoder.Decode(tiers); err != nil {
+		return nil, fmt.Errorf("%s: failed to decode: %w", name, err)
+	}
+
+	if err := tiers.Validate(); err != nil {
+		return nil, prefixErrors(name, err)
+	}
+
+	return tiers, nil
+}
+
+func (c *TierConfig) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	switch c.Mode {
+	case TierModeRules:
+	case TierModePercentile:
+		p := c.Percentile
+		if p.Economic <= 0 || p.Luxury >= 1 || p.Economic >= p.Luxury {
+			invalid("percentile", "economic and luxury must satisfy 0 < economic < luxury < 1, got %v and %v", p.Economic, p.Luxury)
+		}
+		if p.MinSamples < 1 {
+			invalid("percentile.min_samples", "must be at least 1, got %d", p.MinSamples)
+		}
+	default:
+		invalid("mode", "must be %q or %q, got %q", TierModeRules, TierModePercentile, c.Mode)
+	}
+
+	seen := map[string]bool{}
+	check := func(field string, rule TierRule) {
+		if strings.TrimSpace(rule.Name) == "" {
+			invalid(field+".name", "is required")
+		} else if seen[rule.Name] {
+			invalid(field+".name", "duplicate rule %q", rule.Name)
+		}
+		seen[rule.Name] = true
+		if rule.EconomicMax < 0 || rule.LuxuryMin < 0 {
+			invalid(field, "thresholds must not be negative")
+		}
+		if rule.EconomicMax > 0 && rule.LuxuryMin > 0 && rule.EconomicMax >= rule.LuxuryMin {
+			invalid(field, "economic_max %v must be below luxury_min %v", rule.EconomicMax, rule.LuxuryMin)
+		}
+	}
+
+	for i, rule := range c.Rules {
+		check(fmt.Sprintf("rules[%d]", i), rule)
+		if rule.specificity() == 0 {
+			invalid(fmt.Sprintf("rules[%d]", i), "must match on category, subcategory, gender or source; use fallback for the default")
+		}
+	}
+	check("fallback", c.Fallback)
+	if c.Fallback.specificity() > 0 {
+		invalid("fallback", "must not set category, subcategory, gender or source")
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r TierRule) specificity() int {
+	count := 0
+	for _, field := range []string{r.Category, r.Subcategory, r.Gender, r.Source} {
+		if field != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (r TierRule) matches(product *Product) bool {
+	return matchesField(r.Category, product.Category) &&
+		matchesField(r.Subcategory, product.Subcategory) &&
+		matchesField(r.Gender, product.Gender) &&
+		matchesField(r.Source, product.Source)
+}
+
+func matchesField(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// rule returns the most specific rule matching product, preferring earlier
+// rules on ties.
+func (c *TierConfig) rule(product *Product) TierRule {
+	best, bestSpecificity := c.Fallback, 0
+	for _, rule := range c.Rules {
+		if specificity := rule.specificity(); specificity > bestSpecificity && rule.matches(product) {
+			best, bestSpecificity = rule, specificity
+		}
+	}
+	return best
+}
+
+// priceTiers assigns tiers during one run. In percentile mode it holds the
+// thresholds computed from the catalog when the run started.
+type priceTiers struct {
+	config      *TierConfig
+	percentiles map[string]TierRule
+}
+
+func newPriceTiers(config *TierConfig) *priceTiers {
+	return &priceTiers{config: config}
+}
+
+// loadPercentiles computes the percentile thresholds of every category with
+// enough available BRL products.
+func (t *priceTiers) loadPercentiles(ctx context.Context, db *sql.DB) error {
+	p := t.config.Percentile
+	rows, err := db.QueryContext(ctx, `
+		SELECT category,
+			percentile_cont($1) WITHIN GROUP (ORDER BY price),
+			percentile_cont($2) WITHIN GROUP (ORDER BY price)
+		FROM products
+		WHERE is_available = true AND currency = 'BRL'
+		GROUP BY category
+		HAVING COUNT(*) >= $3`,
+		p.Economic, p.Luxury, p.MinSamples)
+	if err != nil {
+		return fmt.Errorf("failed to compute price percentiles: %w", err)
+	}
+	defer rows.Close()
+
+	percentiles := map[string]TierRule{}
+	for rows.Next() {
+		var category string
+		var economicMax, luxuryMin float64
+		if err := rows.Scan(&category, &economicMax, &luxuryMin); err != nil {
+			return fmt.Errorf("failed to scan price percentiles: %w", err)
+		}
+		percentiles[strings.ToLower(category)] = TierRule{
+			Name:        fmt.Sprintf("percentile:%s:p%g-p%g", category, percent(p.Economic), percent(p.Luxury)),
+			Category:    category,
+			EconomicMax: economicMax,
+			LuxuryMin:   luxuryMin,
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read price percentiles: %w", err)
+	}
+
+	t.percentiles = percentiles
+	return nil
+}
+
+// percent formats a fraction such as 0.9 as 90 rather than 90.00000000000001.
+func percent(fraction float64) float64 {
+	return math.Round(fraction*1000) / 10
+}
+
+// assign sets the tier of product from its price in BRL and records the rule
+// that decided it.
+func (t *priceTiers) assign(product *Product, price float64) {
+	rule, ok := t.percentiles[strings.ToLower(product.Category)]
+	if !ok {
+		rule = t.config.rule(product)
+	}
+
+	product.IsEconomic = rule.EconomicMax > 0 && price <= rule.EconomicMax
+	product.IsLuxury = !product.IsEconomic && rule.LuxuryMin > 0 && price >= rule.LuxuryMin
+	product.TierRule = rule.Name
+}
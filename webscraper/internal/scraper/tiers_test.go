@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPriceTiersPickMostSpecificRule(t *testing.T) {
+	tiers, err := ParseTierConfig("tiers.yaml", []byte(`
+rules:
+  - name: dress
+    category: dress
+    economic_max: 120
+    luxury_min: 600
+  - name: dress-female-zara
+    category: dress
+    gender: female
+    source: zara
+    economic_max: 200
+    luxury_min: 900
+  - name: accessory
+    category: accessory
+    economic_max: 50
+    luxury_min: 200
+fallback:
+  name: fallback
+  economic_max: 100
+  luxury_min: 400
+`))
+	if err != nil {
+		t.Fatalf("ParseTierConfig: %v", err)
+	}
+
+	tests := []struct {
+		product  Product
+		price    float64
+		rule     string
+		economic bool
+		luxury   bool
+	}{
+		{Product{Category: "dress", Gender: "female", Source: "renner"}, 150, "dress", false, false},
+		{Product{Category: "dress", Gender: "female", Source: "zara"}, 150, "dress-female-zara", true, false},
+		{Product{Category: "Dress", Gender: "female", Source: "zara"}, 900, "dress-female-zara", false, true},
+		{Product{Category: "accessory"}, 250, "accessory", false, true},
+		{Product{Category: "blouse"}, 90, "fallback", true, false},
+		{Product{Category: "blouse"}, 250, "fallback", false, false},
+	}
+
+	run := newPriceTiers(tiers)
+	for _, tt := range tests {
+		product := tt.product
+		run.assign(&product, tt.price)
+		if product.TierRule != tt.rule || product.IsEconomic != tt.economic || product.IsLuxury != tt.luxury {
+			t.Errorf("%+v at %v: rule %q economic %v luxury %v, want %q %v %v",
+				tt.product, tt.price, product.TierRule, product.IsEconomic, product.IsLuxury, tt.rule, tt.economic, tt.luxury)
+		}
+	}
+}
+
+func TestPriceTiersPreferPercentiles(t *testing.T) {
+	tiers, err := LoadTierConfig("")
+	if err != nil {
+		t.Fatalf("LoadTierConfig: %v", err)
+	}
+
+	run := newPriceTiers(tiers)
+	run.percentiles = map[string]TierRule{
+		"dress": {Name: "percentile:dress:p25-p90", Category: "dress", EconomicMax: 90, LuxuryMin: 350},
+	}
+
+	dress := Product{Category: "dress"}
+	run.assign(&dress, 400)
+	if dress.TierRule != "percentile:dress:p25-p90" || !dress.IsLuxury {
+		t.Errorf("dress = rule %q luxury %v, want the percentile rule and luxury", dress.TierRule, dress.IsLuxury)
+	}
+
+	shirt := Product{Category: "shirt"}
+	run.assign(&shirt, 70)
+	if shirt.TierRule != "shirt" || !shirt.IsEconomic {
+		t.Errorf("shirt = rule %q economic %v, want the bundled shirt rule and economic", shirt.TierRule, shirt.IsEconomic)
+	}
+}
+
+func TestParseTierConfigRejectsInvalidRules(t *testing.T) {
+	_, err := ParseTierConfig("tiers.yaml", []byte(`
+mode: percentile
+percentile:
+  economic: 0.9
+  luxury: 0.25
+rules:
+  - name: any
+    economic_max: 100
+  - name: shirt
+    category: shirt
+    economic_max: 300
+    luxury_min: 100
+  - name: shirt
+    category: shirt
+fallback:
+  category: shirt
+`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, want := range []string{
+		"tiers.yaml: percentile: economic and luxury",
+		"tiers.yaml: percentile.min_samples",
+		"tiers.yaml: rules[0]: must match",
+		"tiers.yaml: rules[1]: economic_max 300 must be below luxury_min 100",
+		`tiers.yaml: rules[2].name: duplicate rule "shirt"`,
+		"tiers.yaml: fallback.name: is required",
+		"tiers.yaml: fallback: must not set",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
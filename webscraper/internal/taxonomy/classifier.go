@@ -0,0 +1,156 @@
+package taxonomy
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Signals name the input that decided a category.
+const (
+	SignalName        = "name"
+	SignalBreadcrumb  = "breadcrumb"
+	SignalURL         = "url"
+	SignalDescription = "description"
+)
+
+// Input holds what a scraper knows about a product. ListingURL is the page
+// the product was listed on and Breadcrumb the listing or category names it
+// was found under, most specific first.
+type Input struct {
+	Name        string
+	URL         string
+	ListingURL  string
+	Breadcrumb  []string
+	Description string
+}
+
+type Result struct {
+	Category    string
+	Subcategory string
+	// Signal is empty when the category is Unknown.
+	Signal string
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// Classify picks the category from the first signal that names one, trying
+// the name, the breadcrumb, the URL paths and the description in that order.
+// Within a text the keyword that appears first wins, so "Jaqueta Jeans" is a
+// jacket and "Calça Jeans" pants. The subcategory is looked up in the same
+// signals, description excluded.
+func Classify(input Input) Result {
+	signals := []struct {
+		name string
+		text string
+	}{
+		{SignalName, Normalize(input.Name)},
+		{SignalBreadcrumb, Normalize(strings.Join(input.Breadcrumb, " | "))},
+		{SignalURL, Normalize(urlPath(input.URL) + " " + urlPath(input.ListingURL))},
+		{SignalDescription, Normalize(input.Description)},
+	}
+
+	for i, signal := range signals {
+		c, ok := findCategory(signal.text)
+		if !ok {
+			continue
+		}
+
+		// A description also mentions other garments ("combine com uma
+		// calça jeans"), so its subcategory keywords are only trusted when
+		// it decided the category.
+		subcategorySignals := signals[:len(signals)-1]
+		if i == len(signals)-1 {
+			subcategorySignals = signals
+		}
+
+		result := Result{Category: c.name, Subcategory: c.subcategories[len(c.subcategories)-1].name, Signal: signal.name}
+		for _, other := range subcategorySignals {
+			if sub, ok := findSubcategory(c, other.text); ok {
+				result.Subcategory = sub
+				break
+			}
+		}
+		return result
+	}
+
+	return Result{Category: Unknown}
+}
+
+// findCategory returns the category of the keyword that appears first in text,
+// preferring longer keywords at the same position.
+func findCategory(text string) (category, bool) {
+	var best category
+	bestIndex, bestLength := -1, 0
+	for _, c := range categories {
+		for _, keyword := range c.keywords {
+			index := indexWord(text, keyword)
+			if index < 0 {
+				continue
+			}
+			if bestIndex < 0 || index < bestIndex || (index == bestIndex && len(keyword) > bestLength) {
+				best, bestIndex, bestLength = c, index, len(keyword)
+			}
+		}
+	}
+	return best, bestIndex >= 0
+}
+
+func findSubcategory(c category, text string) (string, bool) {
+	for _, sub := range c.subcategories {
+		for _, keyword := range sub.keywords {
+			if indexWord(text, keyword) >= 0 {
+				return sub.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// indexWord finds keyword as whole words in text, also in its plural forms.
+// text must be normalized, which leaves single spaces between words.
+func indexWord(text, keyword string) int {
+	padded := " " + text + " "
+	index := -1
+	for _, form := range []string{keyword, keyword + "s", keyword + "es"} {
+		if i := strings.Index(padded, " "+form+" "); i >= 0 && (index < 0 || i < index) {
+			index = i
+		}
+	}
+	return index
+}
+
+// ContainsWord reports whether the normalized text contains one of words, or
+// its plural, as whole words.
+func ContainsWord(text string, words []string) bool {
+	for _, word := range words {
+		if indexWord(text, word) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize lowercases text, drops accents and turns everything but letters
+// and digits into single spaces, so "Calça-Jeans" becomes "calca jeans".
+func Normalize(text string) string {
+	text = accents.Replace(strings.ToLower(text))
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func urlPath(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return parsed.Path
+}
@@ -0,0 +1,154 @@
+package taxonomy
+
+import "testing"
+
+func TestClassifyPortugueseTitles(t *testing.T) {
+	tests := []struct {
+		name        string
+		category    string
+		subcategory string
+	}{
+		{"Camiseta Básica Algodão", Shirt, "t-shirt"},
+		{"Camiseta Manga Longa Térmica", Shirt, "long-sleeve"},
+		{"Camisa Polo Piquet Masculina", Shirt, "polo"},
+		{"Polo Slim Listrada", Shirt, "polo"},
+		{"Camisa Social Linho", Shirt, "button-down"},
+		{"Blusa Ciganinha Estampada", Shirt, "blouse"},
+		{"Regata Nadador Canelada", Shirt, "tank-top"},
+		{"Top Cropped Canelado", Shirt, "cropped"},
+		{"Calça Jeans Skinny", Pants, "jeans"},
+		{"Calça Legging Suplex", Pants, "leggings"},
+		{"Calça Jogger Moletom", Pants, "sweatpants"},
+		{"Calça Alfaiataria Pantalona", Pants, "dress-pants"},
+		{"Short Jeans Destroyed", Shorts, "jeans"},
+		{"Bermuda Sarja Masculina", Shorts, "bermuda"},
+		{"Shorts Fitness com Bolso", Shorts, "sport"},
+		{"Saia Midi Plissada", Skirt, "midi"},
+		{"Saia Jeans Curta", Skirt, "jeans"},
+		{"Vestido Longo Floral", Dress, "long"},
+		{"Vestido Midi de Festa", Dress, "party"},
+		{"Macacão Pantalona Linho", Dress, "jumpsuit"},
+		{"Jaqueta Jeans Oversized", Jacket, "denim"},
+		{"Blusa de Moletom com Capuz", Jacket, "hoodie"},
+		{"Casaco de Lã Batido", Jacket, "coat"},
+		{"Blazer Alongado Alfaiataria", Jacket, "blazer"},
+		{"Cardigan Tricô Decote V", Jacket, "knit"},
+		{"Biquíni Cortininha Estampado", Swimwear, "bikini"},
+		{"Maiô Decote Profundo", Swimwear, "swimsuit"},
+		{"Sunga Boxer Lisa", Swimwear, "trunks"},
+		{"Short de Praia Estampado", Swimwear, "trunks"},
+		{"Kit 3 Cuecas Boxer", Underwear, "briefs"},
+		{"Sutiã Sem Bojo Renda", Underwear, "bra"},
+		{"Meia-Calça Fio 40", Underwear, "socks"},
+		{"Pijama Curto Algodão", Underwear, "sleepwear"},
+		{"Tênis Casual Branco", Shoes, "sneakers"},
+		{"Sandália Salto Bloco", Shoes, "sandals"},
+		{"Bota Coturno Tratorada", Shoes, "boots"},
+		{"Scarpin Verniz", Shoes, "heels"},
+		{"Cinto Couro Fivela Dourada", Accessory, "belt"},
+		{"Boné Aba Curva", Accessory, "hat"},
+		{"Óculos de Sol Aviador", Accessory, "sunglasses"},
+		{"Colar Corrente Dourado", Accessory, "jewelry"},
+		{"Bolsa Transversal Matelassê", Bag, "crossbody"},
+		{"Mochila Notebook", Bag, "backpack"},
+		{"Pochete Nylon", Bag, "belt-bag"},
+	}
+
+	for _, tt := range tests {
+		got := Classify(Input{Name: tt.name})
+		if got.Category != tt.category || got.Subcategory != tt.subcategory || got.Signal != SignalName {
+			t.Errorf("Classify(%q) = %+v, want %s/%s from the name", tt.name, got, tt.category, tt.subcategory)
+		}
+		if !Valid(got.Category, got.Subcategory) {
+			t.Errorf("Classify(%q) = %+v, which is not in the taxonomy", tt.name, got)
+		}
+	}
+}
+
+func TestClassifyFallsBackToOtherSignals(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		want  Result
+	}{
+		{
+			"breadcrumb",
+			Input{Name: "Modelo Ana Estampado", Breadcrumb: []string{"vestidos"}},
+			Result{Category: Dress, Subcategory: "casual", Signal: SignalBreadcrumb},
+		},
+		{
+			"subcategory from breadcrumb",
+			Input{Name: "Calça Skinny Azul", Breadcrumb: []string{"calcas-jeans", "Feminino"}},
+			Result{Category: Pants, Subcategory: "jeans", Signal: SignalName},
+		},
+		{
+			"url path",
+			Input{Name: "Essencial Preta", URL: "https://www.example.com.br/feminino/saias/essencial-preta-123/p?sku=1"},
+			Result{Category: Skirt, Subcategory: "casual", Signal: SignalURL},
+		},
+		{
+			"description",
+			Input{Name: "Peça Coleção Verão", Description: "Biquíni com top triângulo e calcinha asa delta."},
+			Result{Category: Swimwear, Subcategory: "bikini", Signal: SignalDescription},
+		},
+		{
+			"description does not pick the subcategory of a named category",
+			Input{Name: "Vestido Alça Fina", Description: "Combine com uma jaqueta jeans e um vestido longo de festa."},
+			Result{Category: Dress, Subcategory: "casual", Signal: SignalName},
+		},
+		{
+			"unknown",
+			Input{Name: "Kit Presente Especial", URL: "https://www.example.com.br/kit-presente/p"},
+			Result{Category: Unknown},
+		},
+		{
+			"words inside other words do not match",
+			Input{Name: "Bolsos Laterais Botão"},
+			Result{Category: Unknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.input); got != tt.want {
+				t.Errorf("Classify(%+v) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxonomyIsConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range categories {
+		if seen[c.name] {
+			t.Errorf("category %q is defined twice", c.name)
+		}
+		seen[c.name] = true
+
+		if len(c.keywords) == 0 {
+			t.Errorf("%s has no keywords", c.name)
+		}
+		last := c.subcategories[len(c.subcategories)-1]
+		if len(last.keywords) != 0 {
+			t.Errorf("%s must end with a default subcategory without keywords, got %q", c.name, last.name)
+		}
+		for _, sub := range c.subcategories[:len(c.subcategories)-1] {
+			if len(sub.keywords) == 0 {
+				t.Errorf("%s/%s has no keywords", c.name, sub.name)
+			}
+		}
+		for _, keyword := range append(c.keywords, subcategoryKeywords(c)...) {
+			if Normalize(keyword) != keyword {
+				t.Errorf("%s keyword %q is not normalized", c.name, keyword)
+			}
+		}
+	}
+}
+
+func subcategoryKeywords(c category) []string {
+	var keywords []string
+	for _, sub := range c.subcategories {
+		keywords = append(keywords, sub.keywords...)
+	}
+	return keywords
+}
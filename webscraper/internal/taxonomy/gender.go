@@ -0,0 +1,85 @@
+package taxonomy
+
+import "strings"
+
+// Genders allowed by the products table.
+const (
+	Male   = "male"
+	Female = "female"
+	Unisex = "unisex"
+)
+
+type GenderResult struct {
+	Gender string
+	// Confidence goes from 0, when nothing named a gender and Gender is
+	// Unisex by default, to 1.
+	Confidence float64
+	// Kids is set for children's clothing, which is tagged rather than given
+	// a gender of its own.
+	Kids   bool
+	Signal string
+}
+
+var (
+	femaleWords = []string{"feminino", "feminina", "mulher", "women", "woman", "menina", "girl"}
+	maleWords   = []string{"masculino", "masculina", "homem", "homens", "men", "man", "menino", "boy"}
+	unisexWords = []string{"unissex", "unisex", "sem genero", "genderless"}
+
+	// Garments worn by one gender only. Tops such as "camisa" or "blusa"
+	// are worn by everyone and are not listed.
+	femaleGarments = []string{"vestido", "saia", "sutia", "calcinha", "biquini", "maio", "lingerie", "scarpin"}
+	maleGarments   = []string{"cueca", "sunga", "terno", "gravata"}
+
+	// "baby look" is an adult fit, so "baby" is not a kids word.
+	kidsWords = []string{"infantil", "infanto juvenil", "juvenil", "kids", "kid", "crianca", "menino", "menina", "bebe", "teen", "boy", "girl"}
+)
+
+// DetectGender trusts the store's own grouping most: the listing and product
+// URL paths, then the breadcrumb, then gender words in the name and finally
+// garments worn by one gender only. A signal naming both genders makes the
+// product unisex.
+func DetectGender(input Input) GenderResult {
+	urls := Normalize(urlPath(input.ListingURL) + " " + urlPath(input.URL))
+	breadcrumb := Normalize(strings.Join(input.Breadcrumb, " | "))
+	name := Normalize(input.Name)
+
+	result := GenderResult{Gender: Unisex}
+	for _, text := range []string{urls, breadcrumb, name} {
+		if ContainsWord(text, kidsWords) {
+			result.Kids = true
+		}
+	}
+
+	signals := []struct {
+		name       string
+		text       string
+		confidence float64
+		female     []string
+		male       []string
+	}{
+		{SignalURL, urls, 0.9, femaleWords, maleWords},
+		{SignalBreadcrumb, breadcrumb, 0.8, femaleWords, maleWords},
+		{SignalName, name, 0.7, femaleWords, maleWords},
+		{SignalName, name, 0.5, femaleGarments, maleGarments},
+	}
+
+	for _, signal := range signals {
+		female, male := ContainsWord(signal.text, signal.female), ContainsWord(signal.text, signal.male)
+		unisex := ContainsWord(signal.text, unisexWords)
+		if !female && !male && !unisex {
+			continue
+		}
+
+		result.Confidence, result.Signal = signal.confidence, signal.name
+		switch {
+		case unisex || (female && male):
+		case female:
+			result.Gender = Female
+		case male:
+			result.Gender = Male
+		}
+		return result
+	}
+
+	return result
+}
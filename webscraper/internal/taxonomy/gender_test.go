@@ -0,0 +1,75 @@
+package taxonomy
+
+import "testing"
+
+func TestDetectGender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		want  GenderResult
+	}{
+		{
+			"listing url",
+			Input{Name: "Camisa Linho Manga Curta", ListingURL: "https://www.lojasrenner.com.br/c/moda-feminina/camisas"},
+			GenderResult{Gender: Female, Confidence: 0.9, Signal: SignalURL},
+		},
+		{
+			"product url",
+			Input{Name: "Blusa Tricô Gola Alta", URL: "https://www.zara.com/br/pt/homem/blusa-trico-p123.html"},
+			GenderResult{Gender: Male, Confidence: 0.9, Signal: SignalURL},
+		},
+		{
+			"url wins over the name",
+			Input{Name: "Camiseta Masculina Oversized", ListingURL: "https://www.cea.com.br/feminino/blusas-e-camisetas"},
+			GenderResult{Gender: Female, Confidence: 0.9, Signal: SignalURL},
+		},
+		{
+			"category key",
+			Input{Name: "Camisa Social Slim", Breadcrumb: []string{"camisetas-feminino"}},
+			GenderResult{Gender: Female, Confidence: 0.8, Signal: SignalBreadcrumb},
+		},
+		{
+			"name",
+			Input{Name: "Camiseta Feminina Adidas Essentials"},
+			GenderResult{Gender: Female, Confidence: 0.7, Signal: SignalName},
+		},
+		{
+			"garment",
+			Input{Name: "Vestido Midi Estampado"},
+			GenderResult{Gender: Female, Confidence: 0.5, Signal: SignalName},
+		},
+		{
+			"camisa and blusa say nothing",
+			Input{Name: "Camisa Xadrez Flanela"},
+			GenderResult{Gender: Unisex},
+		},
+		{
+			"both genders",
+			Input{Name: "Tênis Casual", Breadcrumb: []string{"Calçados", "Masculino", "Feminino"}},
+			GenderResult{Gender: Unisex, Confidence: 0.8, Signal: SignalBreadcrumb},
+		},
+		{
+			"unissex",
+			Input{Name: "Boné Unissex Aba Reta"},
+			GenderResult{Gender: Unisex, Confidence: 0.7, Signal: SignalName},
+		},
+		{
+			"kids",
+			Input{Name: "Vestido Infantil Floral", ListingURL: "https://www.example.com.br/infantil/menina"},
+			GenderResult{Gender: Female, Confidence: 0.9, Kids: true, Signal: SignalURL},
+		},
+		{
+			"baby look is an adult fit",
+			Input{Name: "Camiseta Baby Look Feminina"},
+			GenderResult{Gender: Female, Confidence: 0.7, Signal: SignalName},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectGender(tt.input); got != tt.want {
+				t.Errorf("DetectGender(%+v) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
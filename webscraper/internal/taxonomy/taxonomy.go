@@ -0,0 +1,227 @@
+// Package taxonomy defines the product categories shared by every scraper and
+// classifies products into them from their Portuguese names, URLs,
+// breadcrumbs and descriptions.
+package taxonomy
+
+const (
+	Shirt     = "shirt"
+	Pants     = "pants"
+	Shorts    = "shorts"
+	Skirt     = "skirt"
+	Dress     = "dress"
+	Jacket    = "jacket"
+	Swimwear  = "swimwear"
+	Underwear = "underwear"
+	Shoes     = "shoes"
+	Accessory = "accessory"
+	Bag       = "bag"
+
+	// Unknown is reported when no signal names a category.
+	Unknown = "unknown"
+)
+
+type category struct {
+	name     string
+	keywords []string
+	// subcategories are tried in order, so more specific ones come first.
+	// The last one has no keywords and is the default.
+	subcategories []subcategory
+}
+
+type subcategory struct {
+	name     string
+	keywords []string
+}
+
+// categories is the taxonomy. Keywords are lowercase and unaccented; a
+// keyword also matches its plural in "s" or "es".
+var categories = []category{
+	{
+		name:     Shirt,
+		keywords: []string{"camiseta", "camisa", "blusa", "polo", "regata", "cropped", "top", "body", "t shirt", "tshirt", "bata", "tunica"},
+		subcategories: []subcategory{
+			{"polo", []string{"polo"}},
+			{"tank-top", []string{"regata", "tank top"}},
+			{"cropped", []string{"cropped"}},
+			{"bodysuit", []string{"body"}},
+			{"long-sleeve", []string{"manga longa"}},
+			{"blouse", []string{"blusa", "bata", "tunica"}},
+			{"button-down", []string{"camisa"}},
+			{"t-shirt", []string{"camiseta", "t shirt", "tshirt"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name:     Pants,
+		keywords: []string{"calca", "legging", "jogger", "pantalona", "jeans"},
+		subcategories: []subcategory{
+			{"leggings", []string{"legging"}},
+			{"sweatpants", []string{"moletom", "jogger"}},
+			{"dress-pants", []string{"social", "alfaiataria"}},
+			{"wide-leg", []string{"pantalona", "wide leg"}},
+			{"cargo", []string{"cargo"}},
+			{"jeans", []string{"jeans"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name:     Shorts,
+		keywords: []string{"short", "bermuda"},
+		subcategories: []subcategory{
+			{"sport", []string{"esportivo", "fitness", "corrida", "academia", "treino"}},
+			{"jeans", []string{"jeans"}},
+			{"bermuda", []string{"bermuda"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name:     Skirt,
+		keywords: []string{"saia"},
+		subcategories: []subcategory{
+			{"jeans", []string{"jeans"}},
+			{"mini", []string{"mini", "curta"}},
+			{"midi", []string{"midi"}},
+			{"long", []string{"longa"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name:     Dress,
+		keywords: []string{"vestido", "macacao", "macaquinho"},
+		subcategories: []subcategory{
+			{"jumpsuit", []string{"macacao", "macaquinho"}},
+			{"party", []string{"festa", "gala"}},
+			{"mini", []string{"mini", "curto"}},
+			{"midi", []string{"midi"}},
+			{"long", []string{"longo"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name: Jacket,
+		keywords: []string{
+			"jaqueta", "casaco", "blazer", "moletom", "cardigan", "sueter", "trico", "blusao", "colete",
+			"parka", "sobretudo", "corta vento", "puffer", "hoodie", "blusa de moletom", "blusa moletom", "blusa de frio",
+		},
+		subcategories: []subcategory{
+			{"hoodie", []string{"moletom", "hoodie", "canguru"}},
+			{"blazer", []string{"blazer"}},
+			{"denim", []string{"jeans"}},
+			{"puffer", []string{"puffer", "matelasse", "acolchoada"}},
+			{"knit", []string{"cardigan", "sueter", "trico"}},
+			{"vest", []string{"colete"}},
+			{"coat", []string{"sobretudo", "casaco", "parka", "trench"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name:     Swimwear,
+		keywords: []string{"biquini", "maio", "sunga", "moda praia", "saida de praia", "short de praia", "bermuda de praia", "short de banho"},
+		subcategories: []subcategory{
+			{"bikini", []string{"biquini"}},
+			{"swimsuit", []string{"maio"}},
+			{"trunks", []string{"sunga", "short de praia", "bermuda de praia", "short de banho"}},
+			{"cover-up", []string{"saida de praia", "kaftan", "canga"}},
+			{"other", nil},
+		},
+	},
+	{
+		name:     Underwear,
+		keywords: []string{"cueca", "calcinha", "sutia", "lingerie", "moda intima", "meias", "soquete", "meia cano", "meia calca", "pijama", "camisola"},
+		subcategories: []subcategory{
+			{"briefs", []string{"cueca"}},
+			{"panties", []string{"calcinha"}},
+			{"bra", []string{"sutia"}},
+			{"socks", []string{"meias", "soquete", "meia cano", "meia calca"}},
+			{"sleepwear", []string{"pijama", "camisola"}},
+			{"other", nil},
+		},
+	},
+	{
+		name: Shoes,
+		keywords: []string{
+			"tenis", "sapato", "sandalia", "bota", "chinelo", "sapatilha", "scarpin", "mocassim", "rasteirinha",
+			"tamanco", "mule", "oxford", "coturno", "slip on", "papete", "calcado", "pantufa",
+		},
+		subcategories: []subcategory{
+			{"sneakers", []string{"tenis", "slip on"}},
+			{"sandals", []string{"sandalia", "rasteirinha", "papete", "tamanco", "mule"}},
+			{"boots", []string{"bota", "coturno"}},
+			{"heels", []string{"scarpin", "salto"}},
+			{"flats", []string{"sapatilha", "mocassim", "oxford"}},
+			{"slippers", []string{"chinelo", "pantufa"}},
+			{"casual", nil},
+		},
+	},
+	{
+		name: Accessory,
+		keywords: []string{
+			"acessorio", "cinto", "bone", "chapeu", "gorro", "oculos", "colar", "brinco", "pulseira", "anel",
+			"bijuteria", "joia", "relogio", "lenco", "cachecol", "echarpe", "luva", "carteira", "gravata", "tiara",
+		},
+		subcategories: []subcategory{
+			{"belt", []string{"cinto"}},
+			{"hat", []string{"bone", "chapeu", "gorro"}},
+			{"sunglasses", []string{"oculos"}},
+			{"jewelry", []string{"colar", "brinco", "pulseira", "anel", "bijuteria", "joia"}},
+			{"watch", []string{"relogio"}},
+			{"scarf", []string{"lenco", "cachecol", "echarpe"}},
+			{"wallet", []string{"carteira"}},
+			{"other", nil},
+		},
+	},
+	{
+		name:     Bag,
+		keywords: []string{"bolsa", "mochila", "pochete", "necessaire", "clutch", "mala", "sacola", "bag"},
+		subcategories: []subcategory{
+			{"backpack", []string{"mochila"}},
+			{"belt-bag", []string{"pochete"}},
+			{"clutch", []string{"clutch"}},
+			{"crossbody", []string{"transversal", "tiracolo"}},
+			{"tote", []string{"tote", "sacola", "shopper"}},
+			{"travel", []string{"mala", "necessaire"}},
+			{"other", nil},
+		},
+	},
+}
+
+// Categories returns the names of every category, excluding Unknown.
+func Categories() []string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Subcategories returns the subcategories allowed under category.
+func Subcategories(categoryName string) []string {
+	c, ok := lookup(categoryName)
+	if !ok {
+		return nil
+	}
+	names := make([]string, len(c.subcategories))
+	for i, sub := range c.subcategories {
+		names[i] = sub.name
+	}
+	return names
+}
+
+// Valid reports whether subcategory belongs to category in the taxonomy.
+func Valid(categoryName, subcategoryName string) bool {
+	for _, sub := range Subcategories(categoryName) {
+		if sub == subcategoryName {
+			return true
+		}
+	}
+	return false
+}
+
+func lookup(name string) (category, bool) {
+	for _, c := range categories {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return category{}, false
+}
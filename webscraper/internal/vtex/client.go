@@ -0,0 +1,173 @@
+// Package vtex reads products from the public catalog API of VTEX stores.
+package vtex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxPageSize is the largest range the search endpoint returns per request.
+const MaxPageSize = 50
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+}
+
+func NewClient(baseURL string, httpClient *http.Client, userAgent string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+		userAgent:  userAgent,
+	}
+}
+
+// Category is a node of the store category tree.
+type Category struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	HasChildren bool       `json:"hasChildren"`
+	Children    []Category `json:"children"`
+}
+
+// SearchQuery selects the products of a category, either by its URL path
+// ("feminino/vestidos") or by a category filter ("C:/1/5/").
+type SearchQuery struct {
+	Path   string
+	Filter string
+	From   int
+	To     int
+}
+
+// SearchPage holds one range of search results. Total is the number of
+// products matching the query, or -1 when the store did not report it.
+type SearchPage struct {
+	Products []Product
+	Total    int
+}
+
+// CategoryTree returns the category tree down to depth levels.
+func (c *Client) CategoryTree(ctx context.Context, depth int) ([]Category, error) {
+	var tree []Category
+	if _, err := c.get(ctx, fmt.Sprintf("/api/catalog_system/pub/category/tree/%d", depth), nil, &tree); err != nil {
+		return nil, fmt.Errorf("failed to fetch category tree: %w", err)
+	}
+	return tree, nil
+}
+
+// Search returns the products in the inclusive range [query.From, query.To].
+func (c *Client) Search(ctx context.Context, query SearchQuery) (SearchPage, error) {
+	if query.To < query.From || query.To-query.From >= MaxPageSize {
+		return SearchPage{}, fmt.Errorf("invalid search range %d-%d, at most %d products per page", query.From, query.To, MaxPageSize)
+	}
+
+	path := "/api/catalog_system/pub/products/search"
+	if trimmed := strings.Trim(query.Path, "/"); trimmed != "" {
+		path += "/" + trimmed
+	}
+
+	params := url.Values{}
+	if query.Filter != "" {
+		params.Set("fq", query.Filter)
+	}
+	params.Set("_from", strconv.Itoa(query.From))
+	params.Set("_to", strconv.Itoa(query.To))
+
+	page := SearchPage{Total: -1}
+	header, err := c.get(ctx, path, params, &page.Products)
+	if err != nil {
+		return SearchPage{}, fmt.Errorf("failed to search products: %w", err)
+	}
+	if total, ok := parseResources(header.Get("resources")); ok {
+		page.Total = total
+	}
+
+	return page, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, target interface{}) (http.Header, error) {
+	requestURL := c.baseURL + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	// The search endpoint answers 206 Partial Content whenever more results
+	// exist beyond the requested range.
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusPartialContent {
+		io.Copy(io.Discard, response.Body)
+		return nil, fmt.Errorf("GET %s: HTTP %d", requestURL, response.StatusCode)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", requestURL, err)
+	}
+	return response.Header, nil
+}
+
+// parseResources reads the total from a "resources: 0-49/230" header.
+func parseResources(value string) (int, bool) {
+	index := strings.LastIndex(value, "/")
+	if index < 0 {
+		return 0, false
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(value[index+1:]))
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+// FindCategory returns the category of tree whose URL path is path, along with
+// the "C:/1/5/" filter that selects it and its descendants.
+func FindCategory(tree []Category, path string) (Category, string, bool) {
+	want := normalizePath(path)
+
+	var find func(nodes []Category, ancestors string) (Category, string, bool)
+	find = func(nodes []Category, ancestors string) (Category, string, bool) {
+		for _, node := range nodes {
+			filter := fmt.Sprintf("%s%d/", ancestors, node.ID)
+			if normalizePath(node.URL) == want {
+				return node, "C:" + filter, true
+			}
+			if found, foundFilter, ok := find(node.Children, filter); ok {
+				return found, foundFilter, true
+			}
+		}
+		return Category{}, "", false
+	}
+
+	return find(tree, "/")
+}
+
+func normalizePath(value string) string {
+	if parsed, err := url.Parse(value); err == nil {
+		value = parsed.Path
+	}
+	return strings.ToLower(strings.Trim(value, "/"))
+}
@@ -0,0 +1,112 @@
+package vtex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const searchResponse = `[{
+  "productId": "1001",
+  "productName": "Camiseta Básica Algodão",
+  "brand": "C&A",
+  "link": "https://www.cea.com.br/camiseta-basica/p",
+  "linkText": "camiseta-basica",
+  "allSpecifications": ["Composição"],
+  "Composição": ["100% Algodão"],
+  "items": [
+    {"itemId": "1", "variations": ["Tamanho", "Cor"], "Tamanho": ["P"], "Cor": ["Branco"],
+     "images": [{"imageUrl": "https://cea.vteximg.com.br/arquivos/1.jpg"}],
+     "sellers": [{"sellerId": "1", "sellerDefault": true, "commertialOffer": {"Price": 39.9, "ListPrice": 49.9, "AvailableQuantity": 3, "IsAvailable": true}}]},
+    {"itemId": "2", "variations": [{"name": "Tamanho", "values": ["M"]}, {"name": "Cor", "values": ["Preto"]}],
+     "sellers": [{"sellerId": "1", "commertialOffer": {"Price": 39.9, "ListPrice": 49.9, "AvailableQuantity": 0}}]}
+  ]
+}]`
+
+func TestSearchDecodesProductsAndTotal(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/catalog_system/pub/products/search/masculino/camisetas" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("resources", "0-49/120")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(searchResponse))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), "test-agent")
+	page, err := client.Search(context.Background(), SearchQuery{Path: "/masculino/camisetas/", From: 0, To: 49})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	if gotQuery != "_from=0&_to=49" {
+		t.Errorf("query = %q, want _from=0&_to=49", gotQuery)
+	}
+	if page.Total != 120 {
+		t.Errorf("Total = %d, want 120", page.Total)
+	}
+	if len(page.Products) != 1 {
+		t.Fatalf("got %d products, want 1", len(page.Products))
+	}
+
+	product := page.Products[0]
+	if got := product.Specification("composição"); got != "100% Algodão" {
+		t.Errorf("Specification = %q, want 100%% Algodão", got)
+	}
+	if len(product.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(product.Items))
+	}
+	if size, color := product.Items[0].Variation("Tamanho"), product.Items[0].Variation("cor"); size != "P" || color != "Branco" {
+		t.Errorf("first item variations = %q/%q, want P/Branco", size, color)
+	}
+	if size := product.Items[1].Variation("Tamanho"); size != "M" {
+		t.Errorf("second item size = %q, want M", size)
+	}
+
+	offer, ok := product.Items[1].Offer()
+	if !ok || offer.Available() {
+		t.Errorf("second item offer = %+v, want an unavailable offer", offer)
+	}
+	if offer, _ := product.Items[0].Offer(); !offer.Available() {
+		t.Errorf("first item offer = %+v, want an available offer", offer)
+	}
+}
+
+func TestSearchRejectsErrorsAndLargeRanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client(), "")
+
+	if _, err := client.Search(context.Background(), SearchQuery{Path: "feminino", From: 0, To: 49}); err == nil {
+		t.Error("expected an error for HTTP 403")
+	}
+	if _, err := client.Search(context.Background(), SearchQuery{Path: "feminino", From: 0, To: MaxPageSize}); err == nil {
+		t.Errorf("expected an error for a range larger than %d", MaxPageSize)
+	}
+}
+
+func TestFindCategory(t *testing.T) {
+	tree := []Category{
+		{ID: 1, Name: "Feminino", URL: "https://www.cea.com.br/feminino", Children: []Category{
+			{ID: 7, Name: "Vestidos", URL: "https://www.cea.com.br/feminino/vestidos"},
+		}},
+		{ID: 2, Name: "Masculino", URL: "https://www.cea.com.br/masculino"},
+	}
+
+	category, filter, ok := FindCategory(tree, "/feminino/vestidos")
+	if !ok || category.ID != 7 || filter != "C:/1/7/" {
+		t.Errorf("FindCategory = %+v, %q, %v; want Vestidos with C:/1/7/", category, filter, ok)
+	}
+
+	if _, _, ok := FindCategory(tree, "infantil"); ok {
+		t.Error("expected no category for an unknown path")
+	}
+}
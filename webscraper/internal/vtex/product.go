@@ -0,0 +1,231 @@
+package vtex
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Product is a catalog product as returned by the search endpoint. Each of its
+// items is a SKU, usually one per size and color combination.
+type Product struct {
+	ProductID   string   `json:"productId"`
+	ProductName string   `json:"productName"`
+	Brand       string   `json:"brand"`
+	Link        string   `json:"link"`
+	LinkText    string   `json:"linkText"`
+	Description string   `json:"description"`
+	Categories  []string `json:"categories"`
+	Items       []Item   `json:"items"`
+
+	// Specifications holds the store-defined product fields listed in
+	// allSpecifications, such as "Composição".
+	Specifications map[string][]string `json:"-"`
+}
+
+type Item struct {
+	ItemID  string   `json:"itemId"`
+	Name    string   `json:"name"`
+	Images  []Image  `json:"images"`
+	Sellers []Seller `json:"sellers"`
+
+	// Variations maps SKU variation names, such as "Tamanho" and "Cor", to
+	// their values.
+	Variations map[string][]string `json:"-"`
+}
+
+type Image struct {
+	ImageURL string `json:"imageUrl"`
+}
+
+type Seller struct {
+	SellerID        string `json:"sellerId"`
+	SellerName      string `json:"sellerName"`
+	SellerDefault   bool   `json:"sellerDefault"`
+	CommertialOffer Offer  `json:"commertialOffer"`
+}
+
+type Offer struct {
+	Price             float64       `json:"Price"`
+	ListPrice         float64       `json:"ListPrice"`
+	AvailableQuantity int           `json:"AvailableQuantity"`
+	IsAvailable       *bool         `json:"IsAvailable"`
+	Installments      []Installment `json:"Installments"`
+}
+
+// Installment is one payment option of an offer. Pix and boleto show up as
+// single installments of their payment system.
+type Installment struct {
+	Value                      float64 `json:"Value"`
+	InterestRate               float64 `json:"InterestRate"`
+	TotalValuePlusInterestRate float64 `json:"TotalValuePlusInterestRate"`
+	NumberOfInstallments       int     `json:"NumberOfInstallments"`
+	PaymentSystemName          string  `json:"PaymentSystemName"`
+	Name                       string  `json:"Name"`
+}
+
+// LongestInstallments returns the plan with the most installments, preferring
+// interest-free ones.
+func (o Offer) LongestInstallments() (Installment, bool) {
+	var best Installment
+	found := false
+	for _, installment := range o.Installments {
+		if installment.NumberOfInstallments < 2 || installment.Value <= 0 {
+			continue
+		}
+		betterRate := installment.InterestRate == 0 && best.InterestRate != 0
+		sameRate := (installment.InterestRate == 0) == (best.InterestRate == 0)
+		if !found || betterRate || (sameRate && installment.NumberOfInstallments > best.NumberOfInstallments) {
+			best, found = installment, true
+		}
+	}
+	return best, found
+}
+
+// CashPrice returns the lowest single-payment Pix or boleto value, or zero
+// when the store offers none below Price.
+func (o Offer) CashPrice() float64 {
+	var cash float64
+	for _, installment := range o.Installments {
+		name := strings.ToLower(installment.PaymentSystemName)
+		if installment.NumberOfInstallments != 1 || (!strings.Contains(name, "pix") && !strings.Contains(name, "boleto")) {
+			continue
+		}
+		if installment.Value > 0 && installment.Value < o.Price && (cash == 0 || installment.Value < cash) {
+			cash = installment.Value
+		}
+	}
+	return cash
+}
+
+// Available reports whether the offer can be bought. Older stores omit
+// IsAvailable, so the quantity decides in that case.
+func (o Offer) Available() bool {
+	if o.IsAvailable != nil {
+		return *o.IsAvailable && o.Price > 0
+	}
+	return o.AvailableQuantity > 0 && o.Price > 0
+}
+
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type plain Product
+	var decoded struct {
+		plain
+		AllSpecifications []string `json:"allSpecifications"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*p = Product(decoded.plain)
+
+	fields, err := dynamicFields(data, decoded.AllSpecifications)
+	if err != nil {
+		return err
+	}
+	p.Specifications = fields
+	return nil
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type plain Item
+	var decoded struct {
+		plain
+		Variations json.RawMessage `json:"variations"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*i = Item(decoded.plain)
+
+	if len(decoded.Variations) == 0 || string(decoded.Variations) == "null" {
+		return nil
+	}
+
+	// Older stores list variation names and store the values under those
+	// names; newer ones embed name/values pairs.
+	var names []string
+	if err := json.Unmarshal(decoded.Variations, &names); err == nil {
+		fields, err := dynamicFields(data, names)
+		if err != nil {
+			return err
+		}
+		i.Variations = fields
+		return nil
+	}
+
+	var pairs []struct {
+		Name   string   `json:"name"`
+		Values []string `json:"values"`
+	}
+	if err := json.Unmarshal(decoded.Variations, &pairs); err != nil {
+		return err
+	}
+	i.Variations = map[string][]string{}
+	for _, pair := range pairs {
+		i.Variations[pair.Name] = pair.Values
+	}
+	return nil
+}
+
+// dynamicFields decodes the string list fields named by names from data.
+func dynamicFields(data []byte, names []string) (map[string][]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	fields := map[string][]string{}
+	for _, name := range names {
+		var values []string
+		if value, exists := raw[name]; exists && json.Unmarshal(value, &values) == nil {
+			fields[name] = values
+		}
+	}
+	return fields, nil
+}
+
+// Offer returns the offer of the default seller.
+func (i Item) Offer() (Offer, bool) {
+	seller, ok := i.DefaultSeller()
+	return seller.CommertialOffer, ok
+}
+
+// DefaultSeller returns the seller marked as default, or the first seller
+// when none is.
+func (i Item) DefaultSeller() (Seller, bool) {
+	for _, seller := range i.Sellers {
+		if seller.SellerDefault {
+			return seller, true
+		}
+	}
+	if len(i.Sellers) > 0 {
+		return i.Sellers[0], true
+	}
+	return Seller{}, false
+}
+
+// Variation returns the first value of the first variation matching one of
+// names, compared case-insensitively.
+func (i Item) Variation(names ...string) string {
+	return firstValue(i.Variations, names)
+}
+
+// Specification returns the first value of the first specification matching
+// one of names, compared case-insensitively.
+func (p Product) Specification(names ...string) string {
+	return firstValue(p.Specifications, names)
+}
+
+func firstValue(fields map[string][]string, names []string) string {
+	for _, name := range names {
+		for key, values := range fields {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				return strings.TrimSpace(values[0])
+			}
+		}
+	}
+	return ""
+}